@@ -0,0 +1,327 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrDifferentMonoid is returned when joining lists created with different monoids.
+var ErrDifferentMonoid = errors.New("skiplist: lists are created with different monoids")
+
+// Monoid defines how to aggregate elements in a list created with Options.Monoid.
+//
+// The Combine must be associative and the Identity must be its identity element,
+// i.e. Combine(Identity, x) and Combine(x, Identity) must be x.
+// Aggregates are always combined in key order, so Combine doesn't need to be commutative.
+//
+// Lists created with different *Monoid pointers cannot be joined,
+// as there is no way to tell whether funcs in two monoids are the same.
+// SumMonoid and CountMonoid always return the same *Monoid, so lists created with them can be joined.
+// MinMonoid and MaxMonoid return a new *Monoid on every call.
+// Share the returned *Monoid among lists to join them.
+//
+// Here is a sample to sum up values in a key range.
+//
+//     list := skiplist.NewWithOptions(skiplist.Int, skiplist.Options{
+//         Monoid: skiplist.SumMonoid(),
+//     })
+//
+//     for i := 1; i <= 100; i++ {
+//         list.Set(i, i)
+//     }
+//
+//     sum := list.Aggregate(10, 20) // sum is float64(165).
+type Monoid struct {
+	Identity interface{}
+	Combine  func(lhs, rhs interface{}) interface{}
+
+	// Lift returns the aggregate of a single element.
+	// If it's nil, the value of the element is used.
+	Lift func(key, value interface{}) interface{}
+}
+
+var sumMonoid = &Monoid{
+	Identity: float64(0),
+	Combine: func(lhs, rhs interface{}) interface{} {
+		return lhs.(float64) + rhs.(float64)
+	},
+	Lift: func(key, value interface{}) interface{} {
+		return toFloat64(value)
+	},
+}
+
+var countMonoid = &Monoid{
+	Identity: 0,
+	Combine: func(lhs, rhs interface{}) interface{} {
+		return lhs.(int) + rhs.(int)
+	},
+	Lift: func(key, value interface{}) interface{} {
+		return 1
+	},
+}
+
+// SumMonoid returns a monoid to sum up values as float64.
+// Values must be of any built-in integer or float type. Otherwise, it panics.
+//
+// It always returns the same *Monoid, which must not be changed.
+func SumMonoid() *Monoid {
+	return sumMonoid
+}
+
+// CountMonoid returns a monoid to count elements as int.
+//
+// It always returns the same *Monoid, which must not be changed.
+func CountMonoid() *Monoid {
+	return countMonoid
+}
+
+// MinMonoid returns a monoid to find the min value compared by c.
+// The identity is nil and nil values are ignored.
+func MinMonoid(c Comparable) *Monoid {
+	return &Monoid{
+		Combine: func(lhs, rhs interface{}) interface{} {
+			if lhs == nil {
+				return rhs
+			}
+
+			if rhs == nil || c.Compare(lhs, rhs) <= 0 {
+				return lhs
+			}
+
+			return rhs
+		},
+	}
+}
+
+// MaxMonoid returns a monoid to find the max value compared by c.
+// The identity is nil and nil values are ignored.
+func MaxMonoid(c Comparable) *Monoid {
+	return &Monoid{
+		Combine: func(lhs, rhs interface{}) interface{} {
+			if lhs == nil {
+				return rhs
+			}
+
+			if rhs == nil || c.Compare(lhs, rhs) >= 0 {
+				return lhs
+			}
+
+			return rhs
+		},
+	}
+}
+
+func toFloat64(value interface{}) float64 {
+	switch val := reflect.ValueOf(value); val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(val.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return val.Float()
+	}
+
+	panic(fmt.Errorf("skiplist: value `%v` is not a number", value))
+}
+
+// aggregator combines elements into aggregates cached on level pointers.
+//
+// The aggregates[i] of an element or the list header is the aggregate of
+// all elements skipped by following levels[i] including levels[i] itself.
+// If levels[i] is nil, it's the identity.
+type aggregator struct {
+	identity interface{}
+	combine  func(lhs, rhs interface{}) interface{}
+	lift     func(elem *Element) interface{}
+}
+
+func newMonoidAggregator(m *Monoid) *aggregator {
+	lift := func(elem *Element) interface{} {
+		return elem.Value
+	}
+
+	if m.Lift != nil {
+		lift = func(elem *Element) interface{} {
+			return m.Lift(elem.key, elem.Value)
+		}
+	}
+
+	return &aggregator{
+		identity: m.Identity,
+		combine:  m.Combine,
+		lift:     lift,
+	}
+}
+
+// Aggregate returns the aggregate of all elements with keys in the closed range [lo, hi].
+// Elements are combined in key order by the monoid set in Options.Monoid.
+// If there is no such element, returns the identity of the monoid.
+//
+// If the list is not created with a monoid, it panics.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Aggregate(lo, hi interface{}) (result interface{}) {
+	if list.monoid == nil {
+		panic("skiplist: list is not created with a monoid")
+	}
+
+	agg := list.agg
+	result = agg.identity
+	elem := list.Ceiling(lo)
+	score := list.calcScore(hi)
+
+	if elem == nil || list.compare(score, hi, elem) < 0 {
+		return
+	}
+
+	result = agg.lift(elem)
+
+	// Climb up through top levels of elements until the next one is out of range.
+	i := elem.Level() - 1
+
+	for next := elem.levels[i]; next != nil && list.compare(score, hi, next) >= 0; next = elem.levels[i] {
+		result = agg.combine(result, elem.aggregates[i])
+		elem = next
+		i = elem.Level() - 1
+	}
+
+	// Elements after elem in range are all below level i.
+	for i--; i >= 0; i-- {
+		for next := elem.levels[i]; next != nil && list.compare(score, hi, next) >= 0; next = elem.levels[i] {
+			result = agg.combine(result, elem.aggregates[i])
+			elem = next
+		}
+	}
+
+	return
+}
+
+// UpdateAggregates updates cached aggregates including elem after elem.Value is changed directly.
+// It's not necessary if the value is changed by Set, Compute, Update or CompareAndSwap.
+// If the list is not created with a monoid or elem is not in the list, it does nothing.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) UpdateAggregates(elem *Element) {
+	if list.agg == nil || elem == nil || elem.list != list {
+		return
+	}
+
+	var maxStaticAllocElems [preallocDefaultMaxLevel]*Element
+	var prevElems []*Element
+
+	if top := list.level; top <= preallocDefaultMaxLevel {
+		prevElems = maxStaticAllocElems[:top]
+	} else {
+		prevElems = make([]*Element, top)
+	}
+
+	max := list.findPrevElems(elem, prevElems)
+	list.updatePrevAggregates(prevElems[:max])
+}
+
+// setAggregator enables aggregates maintained by agg.
+// It must be called before any element is added.
+func (list *SkipList) setAggregator(agg *aggregator) {
+	list.agg = agg
+	list.resizeAggregates()
+}
+
+// resizeAggregates makes aggregates of the list header as many as its levels.
+// Aggregates of new levels are the identity.
+func (list *SkipList) resizeAggregates() {
+	if list.agg == nil || len(list.aggregates) == len(list.levels) {
+		return
+	}
+
+	aggregates := make([]interface{}, len(list.levels))
+
+	for i := copy(aggregates, list.aggregates); i < len(aggregates); i++ {
+		aggregates[i] = list.agg.identity
+	}
+
+	list.aggregates = aggregates
+}
+
+// resetAggregates sets aggregates of header to the identity on all levels.
+func (list *SkipList) resetAggregates(header *elementHeader) {
+	if list.agg == nil {
+		return
+	}
+
+	header.aggregates = make([]interface{}, len(header.levels))
+
+	for i := range header.aggregates {
+		header.aggregates[i] = list.agg.identity
+	}
+}
+
+// updateAggregate recalculates the aggregate of header on level i
+// with aggregates on level i-1.
+func (list *SkipList) updateAggregate(header *elementHeader, i int) {
+	agg := list.agg
+	next := header.levels[i]
+
+	if next == nil {
+		header.aggregates[i] = agg.identity
+		return
+	}
+
+	if i == 0 {
+		header.aggregates[0] = agg.lift(next)
+		return
+	}
+
+	result := header.aggregates[i-1]
+
+	for elem := header.levels[i-1]; elem != next; elem = elem.levels[i-1] {
+		result = agg.combine(result, elem.aggregates[i-1])
+	}
+
+	header.aggregates[i] = result
+}
+
+// linkAggregates updates aggregates of elem and previous elements on every level after elem is linked.
+// If prevElemHeaders is nil, the list header is previous to elem on all levels.
+func (list *SkipList) linkAggregates(elem *Element, prevElemHeaders []*elementHeader) {
+	level := elem.Level()
+
+	if len(elem.aggregates) != level {
+		elem.aggregates = make([]interface{}, level)
+	}
+
+	for i := 0; i < list.level; i++ {
+		if i < level {
+			list.updateAggregate(&elem.elementHeader, i)
+		}
+
+		prevHeader := &list.elementHeader
+
+		if prevElemHeaders != nil {
+			prevHeader = prevElemHeaders[i]
+		}
+
+		list.updateAggregate(prevHeader, i)
+	}
+}
+
+// updatePrevAggregates updates aggregates of previous elements of an element on every level
+// after the element is unlinked or changed.
+// The list header is previous to the element on levels not less than len(prevElems).
+// Levels not in use must have been updated before list.level decreases.
+func (list *SkipList) updatePrevAggregates(prevElems []*Element) {
+	for i := 0; i < list.level; i++ {
+		prevHeader := &list.elementHeader
+
+		if i < len(prevElems) {
+			prevHeader = &prevElems[i].elementHeader
+		}
+
+		list.updateAggregate(prevHeader, i)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+// assertAggregates recalculates aggregates on all level pointers from scratch
+// and compares them with cached ones.
+func assertAggregates(a *assert.A, list *SkipList) {
+	agg := list.agg
+	headers := []*elementHeader{&list.elementHeader}
+
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		headers = append(headers, &elem.elementHeader)
+	}
+
+	for _, header := range headers {
+		a.Equal(len(header.aggregates), len(header.levels))
+
+		for i, next := range header.levels {
+			expected := agg.identity
+
+			for elem := header.levels[0]; next != nil && elem != nil; elem = elem.Next() {
+				expected = agg.combine(expected, agg.lift(elem))
+
+				if elem == next {
+					break
+				}
+			}
+
+			a.Equal(header.aggregates[i], expected)
+		}
+	}
+}
+
+// concatMonoid concatenates values as strings. It's not commutative.
+func concatMonoid() *Monoid {
+	return &Monoid{
+		Identity: "",
+		Combine: func(lhs, rhs interface{}) interface{} {
+			return lhs.(string) + rhs.(string)
+		},
+		Lift: func(key, value interface{}) interface{} {
+			return fmt.Sprint(value, ",")
+		},
+	}
+}
+
+func bruteForceAggregate(list *SkipList, lo, hi interface{}) interface{} {
+	agg := list.agg
+	result := agg.identity
+
+	for elem := list.Ceiling(lo); elem != nil && list.comparable.Compare(elem.key, hi) <= 0; elem = elem.Next() {
+		result = agg.combine(result, agg.lift(elem))
+	}
+
+	return result
+}
+
+func assertRandomAggregates(a *assert.A, list *SkipList, max int) {
+	assertSanity(a, list)
+	assertAggregates(a, list)
+
+	for i := 0; i < 100; i++ {
+		lo := rand.Intn(max+20) - 10
+		hi := lo + rand.Intn(max/2+1)
+		a.Equal(list.Aggregate(lo, hi), bruteForceAggregate(list, lo, hi))
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{
+		Monoid: SumMonoid(),
+	})
+
+	a.Equal(list.Aggregate(0, 100), float64(0))
+
+	for i := 1; i <= 100; i++ {
+		list.Set(i, i)
+	}
+
+	a.Equal(list.Aggregate(10, 20), float64(165))
+	a.Equal(list.Aggregate(-10, 1000), float64(5050))
+	a.Equal(list.Aggregate(50, 50), float64(50))
+	a.Equal(list.Aggregate(20, 10), float64(0))
+	a.Equal(list.Aggregate(101, 200), float64(0))
+	assertRandomAggregates(a, list, 100)
+
+	// Values can be changed by any method of the list.
+	list.Set(10, 1010)
+	list.Compute(11, func(old interface{}, exists bool) (interface{}, bool) {
+		return old.(int) + 1000, true
+	})
+	list.Update(12, func(old interface{}) interface{} {
+		return old.(int) + 1000
+	})
+	list.CompareAndSwap(13, 13, 1013)
+	elem := list.Get(14)
+	elem.Value = 1014
+	list.UpdateAggregates(elem)
+	a.Equal(list.Aggregate(10, 20), float64(5165))
+	assertRandomAggregates(a, list, 100)
+
+	list.Remove(10)
+	list.RemoveFront()
+	list.RemoveBack()
+	list.RemoveByIndex(50)
+	list.Compute(20, func(old interface{}, exists bool) (interface{}, bool) {
+		return nil, false
+	})
+	list.Get(30).Remove()
+	a.Equal(list.Aggregate(10, 20), float64(4135))
+	assertRandomAggregates(a, list, 100)
+
+	list.Rekey(list.Get(40), 40)
+	list.Rekey(list.Get(41), 1000)
+	a.Equal(list.Aggregate(41, 999), list.Aggregate(42, 99))
+	assertRandomAggregates(a, list, 100)
+}
+
+func TestAggregateMonoids(t *testing.T) {
+	a := assert.New(t)
+	count := NewWithOptions(Int, Options{Monoid: CountMonoid()})
+	min := NewWithOptions(Int, Options{Monoid: MinMonoid(Int)})
+	max := NewWithOptions(Int, Options{Monoid: MaxMonoid(Int)})
+	concat := NewWithOptions(Int, Options{Monoid: concatMonoid()})
+	values := rand.Perm(200)
+
+	for i, v := range values {
+		for _, list := range []*SkipList{count, min, max, concat} {
+			list.Set(i, v)
+		}
+	}
+
+	a.Equal(count.Aggregate(10, 19), 10)
+	a.Equal(count.Aggregate(1000, 2000), 0)
+	a.Equal(min.Aggregate(0, 199), 0)
+	a.Equal(max.Aggregate(0, 199), 199)
+	a.Equal(min.Aggregate(1000, 2000), nil)
+	a.Equal(concat.Aggregate(3, 5), fmt.Sprintf("%v,%v,%v,", values[3], values[4], values[5]))
+
+	for _, list := range []*SkipList{count, min, max, concat} {
+		assertRandomAggregates(a, list, 200)
+	}
+
+	sum := NewWithOptions(Int, Options{Monoid: SumMonoid()})
+	sum.Set(1, uint8(1))
+	sum.Set(2, 2.5)
+	sum.Set(3, int64(3))
+	a.Equal(sum.Aggregate(1, 3), 6.5)
+
+	defer func() {
+		a.Assert(recover() != nil)
+	}()
+
+	sum.Set(4, "not a number")
+}
+
+func TestAggregateWithoutMonoid(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+	list.Set(1, 1)
+
+	// It does nothing without a monoid.
+	list.UpdateAggregates(list.Front())
+
+	defer func() {
+		a.Assert(recover() != nil)
+	}()
+
+	list.Aggregate(0, 1)
+}
+
+func TestAggregateJoinBuiltinMonoids(t *testing.T) {
+	a := assert.New(t)
+
+	for _, newMonoid := range []func() *Monoid{SumMonoid, CountMonoid} {
+		left := NewWithOptions(Int, Options{Monoid: newMonoid()})
+		right := NewWithOptions(Int, Options{Monoid: newMonoid()})
+
+		for i := 0; i < 10; i++ {
+			left.Set(i, i)
+			right.Set(i+10, i+10)
+		}
+
+		a.NilError(Join(left, right))
+		a.Equal(left.Len(), 20)
+		assertAggregates(a, left)
+	}
+
+	a.Equal(Join(NewWithOptions(Int, Options{Monoid: MinMonoid(Int)}), NewWithOptions(Int, Options{Monoid: MinMonoid(Int)})), ErrDifferentMonoid)
+}
+
+func TestAggregateBulkOperations(t *testing.T) {
+	a := assert.New(t)
+	monoid := concatMonoid()
+	newList := func(keys ...int) *SkipList {
+		list := NewWithOptions(Int, Options{Monoid: monoid})
+
+		for _, k := range keys {
+			list.Set(k, k)
+		}
+
+		return list
+	}
+	var keys []int
+
+	for i := 0; i < 300; i++ {
+		keys = append(keys, rand.Intn(1000))
+	}
+
+	list := newList(keys...)
+	assertRandomAggregates(a, list, 1000)
+
+	right := list.SplitAt(500)
+	assertRandomAggregates(a, list, 1000)
+	assertRandomAggregates(a, right, 1000)
+
+	a.NilError(Join(list, right))
+	assertRandomAggregates(a, list, 1000)
+	a.Equal(Join(list, newList(2000)), nil)
+	a.Equal(Join(list, NewWithOptions(Int, Options{Monoid: concatMonoid()})), ErrDifferentMonoid)
+	a.Equal(Join(list, New(Int)), ErrDifferentMonoid)
+
+	list.Rebalance()
+	assertRandomAggregates(a, list, 1000)
+
+	list.SetMaxLevel(3)
+	list.Rebalance()
+	assertRandomAggregates(a, list, 1000)
+
+	list.SetMaxLevel(20)
+
+	for i := 0; i < 100; i++ {
+		list.Set(rand.Intn(1000), i)
+	}
+
+	assertRandomAggregates(a, list, 1000)
+
+	other := newList(keys[:100]...)
+	union, err := Union(list, other, nil)
+	a.NilError(err)
+	a.Equal(union.Options().Monoid, monoid)
+	assertRandomAggregates(a, union, 1000)
+
+	intersect, err := Intersect(list, other, nil)
+	a.NilError(err)
+	assertRandomAggregates(a, intersect, 1000)
+
+	data, err := list.MarshalBinary()
+	a.NilError(err)
+	decoded := newList()
+	a.NilError(decoded.UnmarshalBinary(data))
+	assertRandomAggregates(a, decoded, 1000)
+	a.Equal(decoded.Aggregate(0, 1000), list.Aggregate(0, 1000))
+
+	list.Init()
+	a.Equal(list.Aggregate(0, 1000), "")
+	list.Set(1, 1)
+	a.Equal(list.Aggregate(0, 1000), "1,")
+	assertAggregates(a, list)
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	list := NewWithOptions(Int, Options{Monoid: SumMonoid()})
+
+	for i := 0; i < 100000; i++ {
+		list.Set(i, i)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		lo := rand.Intn(100000)
+		list.Aggregate(lo, lo+10000)
+	}
+}
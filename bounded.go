@@ -0,0 +1,193 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"iter"
+)
+
+// EvictionPolicy decides what to do when a new key is set to a full BoundedSkipList.
+type EvictionPolicy int
+
+// Eviction policies of BoundedSkipList.
+const (
+	EvictSmallest EvictionPolicy = iota // Remove the front element to admit a greater key.
+	EvictLargest                        // Remove the back element to admit a less key.
+	RejectNew                           // Don't admit any new key.
+)
+
+// BoundedSkipList is a skip list holding at most a fixed count of elements.
+// When the list is full, setting a new key either evicts an existing element
+// or is rejected according to the EvictionPolicy.
+//
+// With EvictSmallest, the list keeps the greatest keys set so far,
+// which is useful to maintain a top-N leaderboard.
+//
+// BoundedSkipList is not goroutine-safe.
+type BoundedSkipList struct {
+	list     *SkipList
+	capacity int
+	policy   EvictionPolicy
+	onEvict  func(elem *Element)
+}
+
+// NewBounded creates a new bounded skip list with comparable to compare keys.
+// The list holds at most capacity elements.
+// If capacity is not greater than 0, just panic.
+func NewBounded(comparable Comparable, capacity int, policy EvictionPolicy) *BoundedSkipList {
+	if capacity <= 0 {
+		panic("skiplist: capacity must be greater than 0")
+	}
+
+	return &BoundedSkipList{
+		list:     New(comparable),
+		capacity: capacity,
+		policy:   policy,
+	}
+}
+
+// SetEvictCallback sets a callback called with every element evicted by Set.
+// The element is already removed from the list when fn is called.
+// The fn must not change the list.
+func (bl *BoundedSkipList) SetEvictCallback(fn func(elem *Element)) {
+	bl.onEvict = fn
+}
+
+// Capacity returns the max count of elements in the list.
+func (bl *BoundedSkipList) Capacity() int {
+	return bl.capacity
+}
+
+// Policy returns the eviction policy.
+func (bl *BoundedSkipList) Policy() EvictionPolicy {
+	return bl.policy
+}
+
+// Len returns element count in this list.
+func (bl *BoundedSkipList) Len() int {
+	return bl.list.Len()
+}
+
+// Front returns the first element.
+func (bl *BoundedSkipList) Front() *Element {
+	return bl.list.Front()
+}
+
+// Back returns the last element.
+func (bl *BoundedSkipList) Back() *Element {
+	return bl.list.Back()
+}
+
+// Set sets value for the key.
+// If the key exists, updates element's value and admitted is true.
+//
+// If the key doesn't exist and the list is full, the policy decides whether the key is admitted.
+//
+//   - EvictSmallest: if the key is greater than the front key, the front element is evicted.
+//     Otherwise, the key is rejected.
+//   - EvictLargest: if the key is less than the back key, the back element is evicted.
+//     Otherwise, the key is rejected.
+//   - RejectNew: the key is rejected.
+//
+// If the key is rejected, the list is not changed and returns nil and false.
+//
+// The complexity is O(log(N)).
+func (bl *BoundedSkipList) Set(key, value interface{}) (elem *Element, admitted bool) {
+	list := bl.list
+
+	if list.length < bl.capacity {
+		elem = list.Set(key, value)
+		admitted = true
+		return
+	}
+
+	if elem = list.Get(key); elem != nil {
+		elem.Value = value
+		admitted = true
+		return
+	}
+
+	score := list.calcScore(key)
+	var evicted *Element
+
+	switch bl.policy {
+	case EvictSmallest:
+		if list.compare(score, key, list.Front()) < 0 {
+			return
+		}
+
+		evicted = list.RemoveFront()
+
+	case EvictLargest:
+		if list.compare(score, key, list.Back()) > 0 {
+			return
+		}
+
+		evicted = list.RemoveBack()
+
+	default:
+		return
+	}
+
+	elem = list.Set(key, value)
+	admitted = true
+
+	if bl.onEvict != nil {
+		bl.onEvict(evicted)
+	}
+
+	return
+}
+
+// Get returns an element with the key.
+// If the key is not found, returns nil.
+func (bl *BoundedSkipList) Get(key interface{}) *Element {
+	return bl.list.Get(key)
+}
+
+// GetValue returns value of the element with the key.
+func (bl *BoundedSkipList) GetValue(key interface{}) (val interface{}, ok bool) {
+	return bl.list.GetValue(key)
+}
+
+// Find returns the first element that is greater or equal to key.
+func (bl *BoundedSkipList) Find(key interface{}) *Element {
+	return bl.list.Find(key)
+}
+
+// GetByIndex returns the element at the 0-based index.
+func (bl *BoundedSkipList) GetByIndex(index int) *Element {
+	return bl.list.GetByIndex(index)
+}
+
+// IndexOf returns the 0-based index of the element with the key.
+// If the key is not found, returns -1.
+func (bl *BoundedSkipList) IndexOf(key interface{}) int {
+	return bl.list.IndexOf(key)
+}
+
+// Remove removes an element.
+// Returns removed element pointer if found, nil if it's not found.
+// The eviction callback is not called.
+func (bl *BoundedSkipList) Remove(key interface{}) *Element {
+	return bl.list.Remove(key)
+}
+
+// RemoveElement removes the elem from the list.
+// The eviction callback is not called.
+func (bl *BoundedSkipList) RemoveElement(elem *Element) {
+	bl.list.RemoveElement(elem)
+}
+
+// All returns an iterator over keys and values of all elements from front to back.
+// See SkipList.All for details.
+func (bl *BoundedSkipList) All() iter.Seq2[interface{}, interface{}] {
+	return bl.list.All()
+}
+
+// Backward returns an iterator over keys and values of all elements from back to front.
+// See SkipList.Backward for details.
+func (bl *BoundedSkipList) Backward() iter.Seq2[interface{}, interface{}] {
+	return bl.list.Backward()
+}
@@ -0,0 +1,144 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func boundedKeys(bl *BoundedSkipList) (keys []interface{}) {
+	for k := range bl.All() {
+		keys = append(keys, k)
+	}
+
+	return
+}
+
+func TestBoundedEvictSmallest(t *testing.T) {
+	a := assert.New(t)
+	bl := NewBounded(Int, 3, EvictSmallest)
+	var evicted []interface{}
+	bl.SetEvictCallback(func(elem *Element) {
+		a.Assert(!elem.InList())
+		evicted = append(evicted, elem.Key())
+	})
+
+	for _, k := range []int{5, 3, 8} {
+		_, admitted := bl.Set(k, k)
+		a.Assert(admitted)
+	}
+
+	a.Equal(bl.Len(), 3)
+	a.Equal(evicted, []interface{}(nil))
+
+	elem, admitted := bl.Set(1, 1)
+	a.Assert(!admitted)
+	a.Assert(elem == nil)
+	a.Equal(boundedKeys(bl), []interface{}{3, 5, 8})
+
+	elem, admitted = bl.Set(6, 6)
+	a.Assert(admitted)
+	a.Equal(elem.Key(), 6)
+	a.Equal(boundedKeys(bl), []interface{}{5, 6, 8})
+	a.Equal(evicted, []interface{}{3})
+
+	// Existing keys are always updated.
+	elem, admitted = bl.Set(5, "five")
+	a.Assert(admitted)
+	a.Equal(elem.Value, "five")
+	a.Equal(bl.Len(), 3)
+	a.Equal(evicted, []interface{}{3})
+
+	a.Assert(bl.Remove(8) != nil)
+	_, admitted = bl.Set(2, 2)
+	a.Assert(admitted)
+	a.Equal(boundedKeys(bl), []interface{}{2, 5, 6})
+	a.Equal(evicted, []interface{}{3})
+	assertSanity(a, bl.list)
+}
+
+func TestBoundedEvictLargest(t *testing.T) {
+	a := assert.New(t)
+	bl := NewBounded(Int, 3, EvictLargest)
+	var evicted []interface{}
+	bl.SetEvictCallback(func(elem *Element) {
+		evicted = append(evicted, elem.Key())
+	})
+
+	for _, k := range []int{5, 3, 8} {
+		bl.Set(k, k)
+	}
+
+	_, admitted := bl.Set(9, 9)
+	a.Assert(!admitted)
+
+	_, admitted = bl.Set(4, 4)
+	a.Assert(admitted)
+	a.Equal(boundedKeys(bl), []interface{}{3, 4, 5})
+	a.Equal(evicted, []interface{}{8})
+	assertSanity(a, bl.list)
+}
+
+func TestBoundedRejectNew(t *testing.T) {
+	a := assert.New(t)
+	bl := NewBounded(Int, 2, RejectNew)
+	bl.SetEvictCallback(func(elem *Element) {
+		t.Fatalf("unexpected eviction of %v", elem.Key())
+	})
+
+	bl.Set(1, 1)
+	bl.Set(2, 2)
+
+	for _, k := range []int{0, 3} {
+		_, admitted := bl.Set(k, k)
+		a.Assert(!admitted)
+	}
+
+	_, admitted := bl.Set(2, "two")
+	a.Assert(admitted)
+	a.Equal(bl.Capacity(), 2)
+	a.Equal(bl.Policy(), RejectNew)
+	a.Equal(boundedKeys(bl), []interface{}{1, 2})
+}
+
+func TestBoundedTopN(t *testing.T) {
+	a := assert.New(t)
+	const n = 10
+	bl := NewBounded(Int, n, EvictSmallest)
+	keys := rand.Perm(1000)
+	evictions := 0
+	bl.SetEvictCallback(func(elem *Element) {
+		evictions++
+	})
+
+	for _, k := range keys {
+		bl.Set(k, k)
+		a.Assert(bl.Len() <= n)
+	}
+
+	sort.Ints(keys)
+	var expected []interface{}
+
+	for _, k := range keys[len(keys)-n:] {
+		expected = append(expected, k)
+	}
+
+	a.Equal(boundedKeys(bl), expected)
+	a.Assert(evictions < len(keys)-n)
+	assertSanity(a, bl.list)
+}
+
+func TestBoundedInvalidCapacity(t *testing.T) {
+	a := assert.New(t)
+
+	defer func() {
+		a.Assert(recover() != nil)
+	}()
+
+	NewBounded(Int, 0, RejectNew)
+}
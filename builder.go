@@ -0,0 +1,185 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotSorted is returned when building a skip list from keys which are not
+// in strictly ascending order according to the comparable.
+var ErrNotSorted = errors.New("skiplist: keys are not in strictly ascending order")
+
+// Builder builds a skip list from keys in ascending order.
+// All levels of every element are linked in a single pass,
+// so that building a list with N elements costs O(N) instead of O(N*log(N)).
+//
+// Here is a sample to use Builder.
+//
+//     b := skiplist.NewBuilder(skiplist.Int)
+//
+//     for i := 0; i < 100; i++ {
+//         if err := b.Append(i, "value"); err != nil {
+//             // Handle error.
+//         }
+//     }
+//
+//     list := b.Build()
+type Builder struct {
+	comparable Comparable
+	list       *SkipList
+	app        *appender
+}
+
+// NewBuilder creates a new builder with comparable to compare keys.
+func NewBuilder(comparable Comparable) *Builder {
+	b := &Builder{
+		comparable: comparable,
+	}
+	b.reset()
+	return b
+}
+
+func (b *Builder) reset() {
+	b.list = New(b.comparable)
+	b.app = newAppender(b.list)
+}
+
+// Append appends a key and value to the back of the list being built.
+// The key must be greater than all keys appended before.
+// Otherwise, returns an error wrapping ErrNotSorted and the list is not changed.
+//
+// The complexity is O(1) in average.
+func (b *Builder) Append(key, value interface{}) error {
+	list := b.list
+	score := list.calcScore(key)
+
+	if list.back != nil && list.compare(score, key, list.back) <= 0 {
+		return fmt.Errorf("%w: key `%v` is not greater than previous key `%v`", ErrNotSorted, key, list.back.key)
+	}
+
+	b.app.Append(score, key, value)
+	return nil
+}
+
+// Len returns count of elements appended to the list being built.
+func (b *Builder) Len() int {
+	return b.list.Len()
+}
+
+// Build returns the list with all appended elements.
+// The builder is reset to build a new list after that.
+func (b *Builder) Build() (list *SkipList) {
+	list = b.list
+	b.reset()
+	return
+}
+
+// NewFromSorted creates a new skip list with keys and values.
+// The keys must be in strictly ascending order according to the comparable.
+// The values[i] is the value of keys[i] and the len(values) must be equal to len(keys).
+//
+// If keys are out of order or there are duplicated keys,
+// returns an error wrapping ErrNotSorted.
+//
+// The complexity is O(N).
+func NewFromSorted(comparable Comparable, keys, values []interface{}) (list *SkipList, err error) {
+	if len(keys) != len(values) {
+		err = fmt.Errorf("skiplist: len(keys) %v must be equal to len(values) %v", len(keys), len(values))
+		return
+	}
+
+	b := NewBuilder(comparable)
+
+	for i, key := range keys {
+		if err = b.Append(key, values[i]); err != nil {
+			return
+		}
+	}
+
+	list = b.Build()
+	return
+}
+
+// appendable returns true if an element with the key can be appended after list's back.
+// The list must not be empty.
+func (list *SkipList) appendable(score float64, key interface{}) bool {
+	comp := list.compare(score, key, list.back)
+	return comp > 0 || comp == 0 && list.duplicates
+}
+
+// appender appends elements with increasing keys to the back of a list.
+// It remembers the last element on every level so that every append
+// links all levels of the new element in O(1) per level.
+type appender struct {
+	list  *SkipList
+	tails []*elementHeader // Last element pointing to nil on every level.
+	ranks []int            // Rank of tails. List header's rank is 0.
+}
+
+// newAppender creates an appender for an empty list.
+func newAppender(list *SkipList) *appender {
+	max := len(list.levels)
+	tails := make([]*elementHeader, max)
+
+	for i := range tails {
+		tails[i] = &list.elementHeader
+	}
+
+	return &appender{
+		list:  list,
+		tails: tails,
+		ranks: make([]int, max),
+	}
+}
+
+// Append appends a new element to the back of the list.
+// The key must be greater than the key of list's back.
+func (app *appender) Append(score float64, key, value interface{}) (elem *Element) {
+	list := app.list
+	elem = newElement(list, list.randLevel(), score, key, value)
+	app.appendElement(elem)
+	return
+}
+
+// appendElement links elem to the back of the list.
+// All levels of elem must be nil.
+func (app *appender) appendElement(elem *Element) {
+	list := app.list
+	level := elem.Level()
+	rank := list.length + 1
+	elem.prev = list.back
+	elem.prevTopLevel = nil
+
+	if prev := app.tails[level-1]; prev != &list.elementHeader {
+		elem.prevTopLevel = prev.Element()
+	}
+
+	// As the last element, elem has no aggregate on all levels.
+	list.resetAggregates(&elem.elementHeader)
+
+	for i := 0; i < level; i++ {
+		app.tails[i].levels[i] = elem
+		app.tails[i].spans[i] = rank - app.ranks[i]
+
+		if list.agg != nil {
+			list.updateAggregate(app.tails[i], i)
+		}
+
+		app.tails[i] = &elem.elementHeader
+		app.ranks[i] = rank
+	}
+
+	list.back = elem
+	list.length++
+
+	if level > list.level {
+		list.level = level
+	}
+
+	if list.debug {
+		list.debugCheck(elem)
+	}
+}
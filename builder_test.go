@@ -0,0 +1,89 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestBuilder(t *testing.T) {
+	a := assert.New(t)
+	b := NewBuilder(Int)
+	a.Equal(b.Len(), 0)
+
+	const N = 10000
+
+	for i := 0; i < N; i++ {
+		a.NilError(b.Append(i*2, i))
+	}
+
+	a.Equal(b.Len(), N)
+	err := b.Append(N, "out of order")
+	a.Assert(errors.Is(err, ErrNotSorted))
+	err = b.Append((N-1)*2, "duplicated")
+	a.Assert(errors.Is(err, ErrNotSorted))
+	a.Equal(b.Len(), N)
+
+	list := b.Build()
+	a.Equal(list.Len(), N)
+	a.Equal(b.Len(), 0)
+	assertSanity(a, list)
+
+	for i := 0; i < N; i++ {
+		a.Equal(list.MustGetValue(i*2), i)
+	}
+
+	a.Equal(list.GetByIndex(N/2).Key(), N)
+
+	// Built list works as usual.
+	list.Set(3, "three")
+	list.Remove(0)
+	list.Set(N*3, "back")
+	assertSanity(a, list)
+
+	// Builder is reset after Build.
+	a.NilError(b.Append(-1, nil))
+	a.Equal(b.Build().Len(), 1)
+	a.Equal(list.Len(), N+1)
+}
+
+func TestNewFromSorted(t *testing.T) {
+	a := assert.New(t)
+
+	list, err := NewFromSorted(StringDesc, []interface{}{"c", "b", "a"}, []interface{}{3, 2, 1})
+	a.NilError(err)
+	a.Equal(list.Len(), 3)
+	a.Equal(list.Front().Value, 3)
+	a.Equal(list.Back().Value, 1)
+	assertSanity(a, list)
+
+	list, err = NewFromSorted(String, nil, nil)
+	a.NilError(err)
+	a.Equal(list.Len(), 0)
+
+	_, err = NewFromSorted(String, []interface{}{"a", "c", "b"}, []interface{}{1, 2, 3})
+	a.Assert(errors.Is(err, ErrNotSorted))
+
+	_, err = NewFromSorted(String, []interface{}{"a", "a"}, []interface{}{1, 2})
+	a.Assert(errors.Is(err, ErrNotSorted))
+
+	_, err = NewFromSorted(String, []interface{}{"a", "b"}, []interface{}{1})
+	a.NonNilError(err)
+}
+
+func BenchmarkNewFromSorted(b *testing.B) {
+	keys := make([]interface{}, b.N)
+	values := make([]interface{}, b.N)
+
+	for i := range keys {
+		keys[i] = i
+		values[i] = i
+	}
+
+	b.ResetTimer()
+	NewFromSorted(Int, keys, values)
+}
@@ -0,0 +1,193 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"fmt"
+	"math"
+)
+
+// Rules checked by CheckComparable.
+const (
+	RuleReflexivity  = "reflexivity"  // Compare(k, k) must be 0.
+	RuleAntisymmetry = "antisymmetry" // Compare(k1, k2) and Compare(k2, k1) must have opposite signs.
+	RuleTransitivity = "transitivity" // If k1 <= k2 and k2 <= k3, k1 <= k3 must be true.
+	RuleScore        = "score"        // Scores must follow the rules in Scorable.
+)
+
+// ComparableError is the error returned by CheckComparable
+// or the value of panic in debug mode when a comparable breaks a rule.
+type ComparableError struct {
+	Rule   string        // The broken rule.
+	Keys   []interface{} // Offending keys.
+	Detail string        // Results of Compare or CalcScore breaking the rule.
+}
+
+func (err *ComparableError) Error() string {
+	return fmt.Sprintf("skiplist: comparable breaks %v rule with keys %v: %v", err.Rule, err.Keys, err.Detail)
+}
+
+// CheckComparable checks whether c is a valid comparable with samples.
+// Every key and every pair of keys in samples are checked against following rules.
+//
+//   - Compare(k, k) is 0.
+//   - Compare(k1, k2) and Compare(k2, k1) have opposite signs.
+//   - Compare is transitive. If k1 < k2 and k2 < k3, k1 < k3 must be true.
+//   - CalcScore follows the rules documented in Scorable.
+//
+// Returns a *ComparableError with offending keys for the first broken rule.
+//
+// All triples of samples are checked for transitivity.
+// The complexity is O(N^3), so samples should not be too many.
+func CheckComparable(c Comparable, samples []interface{}) error {
+	scores := make([]float64, len(samples))
+
+	for i, k := range samples {
+		scores[i] = c.CalcScore(k)
+
+		if err := checkReflexivity(c, k); err != nil {
+			return err
+		}
+	}
+
+	for i, k1 := range samples {
+		for j := i + 1; j < len(samples); j++ {
+			if err := checkPair(c, k1, samples[j], scores[i], scores[j]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, k1 := range samples {
+		for _, k2 := range samples {
+			for _, k3 := range samples {
+				if err := checkTransitivity(c, k1, k2, k3); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkReflexivity(c Comparable, k interface{}) error {
+	if comp := c.Compare(k, k); comp != 0 {
+		return &ComparableError{
+			Rule:   RuleReflexivity,
+			Keys:   []interface{}{k},
+			Detail: fmt.Sprintf("Compare(k, k) = %v", comp),
+		}
+	}
+
+	return nil
+}
+
+// checkPair checks antisymmetry and scores of k1 and k2.
+func checkPair(c Comparable, k1, k2 interface{}, score1, score2 float64) error {
+	comp1 := c.Compare(k1, k2)
+	comp2 := c.Compare(k2, k1)
+
+	if sign(comp1) != -sign(comp2) {
+		return &ComparableError{
+			Rule:   RuleAntisymmetry,
+			Keys:   []interface{}{k1, k2},
+			Detail: fmt.Sprintf("Compare(k1, k2) = %v and Compare(k2, k1) = %v", comp1, comp2),
+		}
+	}
+
+	if comp1 > 0 && score1 >= score2 || comp1 < 0 && score1 <= score2 || comp1 == 0 && score1 == score2 {
+		return nil
+	}
+
+	if math.IsNaN(score1) && math.IsNaN(score2) && comp1 == 0 {
+		return nil
+	}
+
+	return &ComparableError{
+		Rule:   RuleScore,
+		Keys:   []interface{}{k1, k2},
+		Detail: fmt.Sprintf("Compare(k1, k2) = %v but CalcScore(k1) = %v and CalcScore(k2) = %v", comp1, score1, score2),
+	}
+}
+
+// checkTransitivity checks whether k1 <= k3 if k1 <= k2 and k2 <= k3.
+// If either of the first two is strict, k1 < k3 must be true.
+func checkTransitivity(c Comparable, k1, k2, k3 interface{}) error {
+	comp12 := sign(c.Compare(k1, k2))
+	comp23 := sign(c.Compare(k2, k3))
+
+	if comp12 > 0 || comp23 > 0 {
+		return nil
+	}
+
+	comp13 := sign(c.Compare(k1, k3))
+	expected := comp12 + comp23
+
+	if expected < 0 && comp13 < 0 || expected == 0 && comp13 == 0 {
+		return nil
+	}
+
+	return &ComparableError{
+		Rule:   RuleTransitivity,
+		Keys:   []interface{}{k1, k2, k3},
+		Detail: fmt.Sprintf("signs of Compare(k1, k2), Compare(k2, k3) and Compare(k1, k3) are %v, %v and %v", comp12, comp23, comp13),
+	}
+}
+
+// debugCheck checks elem with its previous and next elements.
+// It panics with a *ComparableError if the comparable breaks any rule.
+func (list *SkipList) debugCheck(elem *Element) {
+	c := list.comparable
+	keys := []interface{}{elem.key}
+	scores := []float64{elem.score}
+
+	if prev := elem.prev; prev != nil {
+		keys = append([]interface{}{prev.key}, keys...)
+		scores = append([]float64{prev.score}, scores...)
+	}
+
+	if next := elem.Next(); next != nil {
+		keys = append(keys, next.key)
+		scores = append(scores, next.score)
+	}
+
+	err := checkReflexivity(c, elem.key)
+
+	for i := 1; i < len(keys) && err == nil; i++ {
+		err = checkPair(c, keys[i-1], keys[i], scores[i-1], scores[i])
+	}
+
+	if err == nil && len(keys) == 3 {
+		err = checkTransitivity(c, keys[0], keys[1], keys[2])
+	}
+
+	// Elements are placed by comparing keys with some other elements.
+	// The order with neighbors can be wrong only if Compare is not transitive.
+	for i := 1; i < len(keys) && err == nil; i++ {
+		if comp := c.Compare(keys[i-1], keys[i]); comp > 0 {
+			err = &ComparableError{
+				Rule:   RuleTransitivity,
+				Keys:   []interface{}{keys[i-1], keys[i]},
+				Detail: fmt.Sprintf("Compare(k1, k2) = %v but k1 is placed before k2 in the list", comp),
+			}
+		}
+	}
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func sign(n int) int {
+	if n > 0 {
+		return 1
+	}
+
+	if n < 0 {
+		return -1
+	}
+
+	return 0
+}
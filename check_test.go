@@ -0,0 +1,101 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+type lossyComparable struct{}
+
+func (lossyComparable) Compare(lhs, rhs interface{}) int {
+	return String.Compare(lhs, rhs)
+}
+
+// CalcScore uses the length of key as score, which is not consistent with Compare.
+func (lossyComparable) CalcScore(key interface{}) float64 {
+	return float64(len(key.(string)))
+}
+
+func TestCheckComparable(t *testing.T) {
+	a := assert.New(t)
+	samples := []interface{}{3, 1, 4, 1, 5, 9, 2, 6}
+
+	a.NilError(CheckComparable(Int, samples))
+	a.NilError(CheckComparable(Reverse(Int), samples))
+	a.NilError(CheckComparable(IntDesc, samples))
+	a.NilError(CheckComparable(String, []interface{}{"", "a", "ab", "b", "ba"}))
+	a.NilError(CheckComparable(Int, nil))
+
+	var err *ComparableError
+
+	a.Assert(errors.As(CheckComparable(lossyComparable{}, []interface{}{"a", "b", "ab"}), &err))
+	a.Equal(err.Rule, RuleScore)
+	a.Equal(err.Keys, []interface{}{"b", "ab"})
+	a.Equal(err.Error(), "skiplist: comparable breaks score rule with keys [b ab]: Compare(k1, k2) = 1 but CalcScore(k1) = 1 and CalcScore(k2) = 2")
+
+	alwaysLess := GreaterThanFunc(func(lhs, rhs interface{}) int {
+		return -1
+	})
+	a.Assert(errors.As(CheckComparable(alwaysLess, []interface{}{1}), &err))
+	a.Equal(err.Rule, RuleReflexivity)
+	a.Equal(err.Keys, []interface{}{1})
+
+	notSymmetric := GreaterThanFunc(func(lhs, rhs interface{}) int {
+		if lhs.(int) == rhs.(int) {
+			return 0
+		}
+
+		return 1
+	})
+	a.Assert(errors.As(CheckComparable(notSymmetric, []interface{}{1, 2}), &err))
+	a.Equal(err.Rule, RuleAntisymmetry)
+	a.Equal(err.Keys, []interface{}{1, 2})
+
+	// Rock, paper and scissors.
+	rps := GreaterThanFunc(func(lhs, rhs interface{}) int {
+		l, r := lhs.(int), rhs.(int)
+
+		if l == r {
+			return 0
+		}
+
+		if (l+1)%3 == r {
+			return -1
+		}
+
+		return 1
+	})
+	a.Assert(errors.As(CheckComparable(rps, []interface{}{0, 1, 2}), &err))
+	a.Equal(err.Rule, RuleTransitivity)
+	a.Equal(err.Keys, []interface{}{0, 1, 2})
+}
+
+func TestDebug(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{Debug: true})
+	a.Assert(list.Options().Debug)
+
+	for i := 0; i < 100; i++ {
+		list.Set(i*7%100, i)
+	}
+
+	assertSanity(a, list)
+
+	list = NewWithOptions(lossyComparable{}, Options{Debug: true})
+	list.Set("b", 1)
+
+	defer func() {
+		r := recover()
+		err, ok := r.(*ComparableError)
+		a.Assert(ok)
+		a.Equal(err.Rule, RuleScore)
+		a.Equal(err.Keys, []interface{}{"b", "ab"})
+	}()
+
+	list.Set("ab", 2)
+}
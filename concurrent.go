@@ -0,0 +1,395 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"iter"
+	"math/rand"
+	"sync"
+)
+
+// ConcurrentSkipList is a skip list protected by a reader/writer lock.
+// All methods are safe to be called by multiple goroutines.
+//
+// Methods returning *Element return elements in the list at the moment.
+// Element's Value can be read safely only if no other goroutine sets the same key.
+// Walking through elements by Next or Prev without the lock is not safe.
+// Use View, All or other iterators instead.
+// Element's Remove takes the write lock and Element's List returns nil
+// to keep the underlying list from being accessed without the lock.
+type ConcurrentSkipList struct {
+	mu   sync.RWMutex
+	list *SkipList
+}
+
+// NewConcurrent creates a new concurrent skip list with comparable to compare keys.
+func NewConcurrent(comparable Comparable) *ConcurrentSkipList {
+	cl := &ConcurrentSkipList{
+		list: New(comparable),
+	}
+	cl.list.owner = cl
+	return cl
+}
+
+// View calls fn with the underlying list under the read lock.
+// The fn must not change the list or call any method of cl.
+func (cl *ConcurrentSkipList) View(fn func(list *SkipList)) {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	fn(cl.list)
+}
+
+// Write calls fn with the underlying list under the write lock.
+// The fn must not call any method of cl.
+func (cl *ConcurrentSkipList) Write(fn func(list *SkipList)) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	fn(cl.list)
+}
+
+// Init resets the list and discards all existing elements.
+func (cl *ConcurrentSkipList) Init() *ConcurrentSkipList {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.list.Init()
+	return cl
+}
+
+// SetRandSource sets a new rand source.
+// The source is always used under the write lock.
+func (cl *ConcurrentSkipList) SetRandSource(source rand.Source) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.list.SetRandSource(source)
+}
+
+// Front returns the first element.
+func (cl *ConcurrentSkipList) Front() *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Front()
+}
+
+// Back returns the last element.
+func (cl *ConcurrentSkipList) Back() *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Back()
+}
+
+// Len returns element count in this list.
+func (cl *ConcurrentSkipList) Len() int {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Len()
+}
+
+// Set sets value for the key.
+// See SkipList.Set for details.
+func (cl *ConcurrentSkipList) Set(key, value interface{}) *Element {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.Set(key, value)
+}
+
+// GetOrSet returns the element with the key if it exists, or sets value for the key.
+// See SkipList.GetOrSet for details.
+func (cl *ConcurrentSkipList) GetOrSet(key, value interface{}) (elem *Element, loaded bool) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.GetOrSet(key, value)
+}
+
+// Compute calls fn with current value of the key and sets or removes the key by the result of fn.
+// The fn is called under the write lock and must not call any method of cl.
+// See SkipList.Compute for details.
+func (cl *ConcurrentSkipList) Compute(key interface{}, fn func(old interface{}, exists bool) (value interface{}, keep bool)) *Element {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.Compute(key, fn)
+}
+
+// Update sets the value of the key to the result of fn if the key exists.
+// The fn is called under the write lock and must not call any method of cl.
+// See SkipList.Update for details.
+func (cl *ConcurrentSkipList) Update(key interface{}, fn func(old interface{}) interface{}) *Element {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.Update(key, fn)
+}
+
+// CompareAndSwap sets the value of the key to new if current value is equal to old.
+// See SkipList.CompareAndSwap for details.
+func (cl *ConcurrentSkipList) CompareAndSwap(key, old, new interface{}) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.CompareAndSwap(key, old, new)
+}
+
+// FindNext returns the first element after start that is greater or equal to key.
+// See SkipList.FindNext for details.
+func (cl *ConcurrentSkipList) FindNext(start *Element, key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.FindNext(start, key)
+}
+
+// Find returns the first element that is greater or equal to key.
+func (cl *ConcurrentSkipList) Find(key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Find(key)
+}
+
+// FindPrev returns the last element before start that is less or equal to key.
+// See SkipList.FindPrev for details.
+func (cl *ConcurrentSkipList) FindPrev(start *Element, key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.FindPrev(start, key)
+}
+
+// Floor returns the last element that is less or equal to key.
+func (cl *ConcurrentSkipList) Floor(key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Floor(key)
+}
+
+// Ceiling returns the first element that is greater or equal to key.
+func (cl *ConcurrentSkipList) Ceiling(key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Ceiling(key)
+}
+
+// Lower returns the last element that is strictly less than key.
+func (cl *ConcurrentSkipList) Lower(key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Lower(key)
+}
+
+// Higher returns the first element that is strictly greater than key.
+func (cl *ConcurrentSkipList) Higher(key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Higher(key)
+}
+
+// Get returns an element with the key.
+// If the key is not found, returns nil.
+func (cl *ConcurrentSkipList) Get(key interface{}) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Get(key)
+}
+
+// GetValue returns value of the element with the key.
+// It's safe to use the value even if other goroutines set the same key.
+func (cl *ConcurrentSkipList) GetValue(key interface{}) (val interface{}, ok bool) {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.GetValue(key)
+}
+
+// MustGetValue returns value of the element with the key.
+// It will panic if the key doesn't exist in the list.
+func (cl *ConcurrentSkipList) MustGetValue(key interface{}) interface{} {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.MustGetValue(key)
+}
+
+// GetByIndex returns the element at the 0-based index.
+func (cl *ConcurrentSkipList) GetByIndex(index int) *Element {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.GetByIndex(index)
+}
+
+// IndexOf returns the 0-based index of the element with the key.
+// If the key is not found, returns -1.
+func (cl *ConcurrentSkipList) IndexOf(key interface{}) int {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.IndexOf(key)
+}
+
+// Remove removes an element.
+// Returns removed element pointer if found, nil if it's not found.
+func (cl *ConcurrentSkipList) Remove(key interface{}) *Element {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.Remove(key)
+}
+
+// RemoveFront removes front element node and returns the removed element.
+func (cl *ConcurrentSkipList) RemoveFront() *Element {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.RemoveFront()
+}
+
+// RemoveBack removes back element node and returns the removed element.
+func (cl *ConcurrentSkipList) RemoveBack() *Element {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.RemoveBack()
+}
+
+// RemoveElement removes the elem from the list.
+func (cl *ConcurrentSkipList) RemoveElement(elem *Element) {
+	cl.removeElement(elem)
+}
+
+// removeElement removes the elem from the list.
+// Returns false if elem is not in the list.
+func (cl *ConcurrentSkipList) removeElement(elem *Element) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if elem == nil || elem.list != cl.list {
+		return false
+	}
+
+	cl.list.RemoveElement(elem)
+	return true
+}
+
+// Rekey changes the key of elem to key and moves elem to keep the list sorted.
+// See SkipList.Rekey for details.
+func (cl *ConcurrentSkipList) Rekey(elem *Element, key interface{}) bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.Rekey(elem, key)
+}
+
+// RemoveByIndex removes the element at the 0-based index and returns the removed element.
+func (cl *ConcurrentSkipList) RemoveByIndex(index int) *Element {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.RemoveByIndex(index)
+}
+
+// MaxLevel returns current max level value.
+func (cl *ConcurrentSkipList) MaxLevel() int {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.MaxLevel()
+}
+
+// SetMaxLevel changes skip list max level.
+// If level is not greater than 0, just panic.
+func (cl *ConcurrentSkipList) SetMaxLevel(level int) (old int) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.SetMaxLevel(level)
+}
+
+// Stats returns the statistics of levels and search costs of the list.
+// See SkipList.Stats for details.
+func (cl *ConcurrentSkipList) Stats() Stats {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+
+	return cl.list.Stats()
+}
+
+// Rebalance reassigns levels of all elements to the ideal distribution.
+// See SkipList.Rebalance for details.
+func (cl *ConcurrentSkipList) Rebalance() (before, after LevelStats) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.list.Rebalance()
+}
+
+// All returns an iterator over keys and values of all elements from front to back.
+//
+// The read lock is held during the whole iteration.
+// The loop body must not call any method of cl, even a read-only one.
+// Otherwise, it deadlocks once a writer is waiting for the lock.
+// To change the list, collect keys in the loop and change the list after the loop.
+func (cl *ConcurrentSkipList) All() iter.Seq2[interface{}, interface{}] {
+	return cl.readLocked2(cl.list.All())
+}
+
+// Backward returns an iterator over keys and values of all elements from back to front.
+// The read lock is held during the whole iteration.
+// The loop body must not call any method of cl. See All for details.
+func (cl *ConcurrentSkipList) Backward() iter.Seq2[interface{}, interface{}] {
+	return cl.readLocked2(cl.list.Backward())
+}
+
+// Between returns an iterator over keys and values of elements
+// with keys in the closed range [lo, hi] from front to back.
+// The read lock is held during the whole iteration.
+// The loop body must not call any method of cl. See All for details.
+func (cl *ConcurrentSkipList) Between(lo, hi interface{}) iter.Seq2[interface{}, interface{}] {
+	return cl.readLocked2(cl.list.Between(lo, hi))
+}
+
+// Keys returns an iterator over keys of all elements from front to back.
+// The read lock is held during the whole iteration.
+// The loop body must not call any method of cl. See All for details.
+func (cl *ConcurrentSkipList) Keys() iter.Seq[interface{}] {
+	return cl.readLocked(cl.list.Keys())
+}
+
+// Values returns an iterator over values of all elements from front to back.
+// The read lock is held during the whole iteration.
+// The loop body must not call any method of cl. See All for details.
+func (cl *ConcurrentSkipList) Values() iter.Seq[interface{}] {
+	return cl.readLocked(cl.list.Values())
+}
+
+func (cl *ConcurrentSkipList) readLocked(seq iter.Seq[interface{}]) iter.Seq[interface{}] {
+	return func(yield func(v interface{}) bool) {
+		cl.mu.RLock()
+		defer cl.mu.RUnlock()
+
+		seq(yield)
+	}
+}
+
+func (cl *ConcurrentSkipList) readLocked2(seq iter.Seq2[interface{}, interface{}]) iter.Seq2[interface{}, interface{}] {
+	return func(yield func(k, v interface{}) bool) {
+		cl.mu.RLock()
+		defer cl.mu.RUnlock()
+
+		seq(yield)
+	}
+}
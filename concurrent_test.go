@@ -0,0 +1,135 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestConcurrentSkipList(t *testing.T) {
+	a := assert.New(t)
+	cl := NewConcurrent(Int)
+	cl.SetRandSource(rand.NewSource(0x5eed))
+
+	elem := cl.Set(10, "ten")
+	cl.Set(20, "twenty")
+	cl.Set(30, "thirty")
+
+	a.Equal(cl.Len(), 3)
+	a.Equal(cl.Front(), elem)
+	a.Equal(cl.Back().Key(), 30)
+	a.Equal(cl.Get(10), elem)
+	a.Equal(cl.Find(15).Key(), 20)
+	a.Equal(cl.FindNext(elem, 25).Key(), 30)
+	a.Equal(cl.FindPrev(nil, 25).Key(), 20)
+	a.Equal(cl.Floor(25).Key(), 20)
+	a.Equal(cl.Ceiling(25).Key(), 30)
+	a.Equal(cl.Lower(20).Key(), 10)
+	a.Equal(cl.Higher(20).Key(), 30)
+	a.Equal(cl.GetByIndex(1).Key(), 20)
+	a.Equal(cl.IndexOf(30), 2)
+	a.Equal(cl.MustGetValue(20), "twenty")
+
+	v, ok := cl.GetValue(30)
+	a.Assert(ok)
+	a.Equal(v, "thirty")
+
+	keys := []interface{}{}
+
+	for k := range cl.All() {
+		keys = append(keys, k)
+	}
+
+	a.Equal(keys, []interface{}{10, 20, 30})
+
+	cl.View(func(list *SkipList) {
+		a.Equal(list.Len(), 3)
+		assertSanity(a, list)
+	})
+	cl.Write(func(list *SkipList) {
+		list.Set(40, "forty")
+	})
+
+	a.Equal(cl.RemoveByIndex(3).Key(), 40)
+	a.Equal(cl.RemoveFront(), elem)
+	a.Equal(cl.RemoveBack().Key(), 30)
+	a.Equal(cl.Remove(20).Key(), 20)
+	a.Equal(cl.Len(), 0)
+
+	cl.Set(1, 1)
+	cl.RemoveElement(cl.Front())
+	a.Equal(cl.Len(), 0)
+
+	// Elements in a concurrent list don't expose the inner list and remove themselves under the lock.
+	elem = cl.Set(2, 2)
+	a.Assert(elem.List() == nil)
+	a.Assert(elem.InList())
+	a.Assert(elem.Remove())
+	a.Assert(!elem.InList())
+	a.Assert(!elem.Remove())
+	a.Equal(cl.Len(), 0)
+
+	a.Equal(cl.SetMaxLevel(16), DefaultMaxLevel)
+	a.Equal(cl.MaxLevel(), 16)
+	a.Equal(cl.Init().Len(), 0)
+}
+
+func TestConcurrentSkipListParallel(t *testing.T) {
+	a := assert.New(t)
+	cl := NewConcurrent(Int)
+
+	const workers = 8
+	const N = 2000
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+
+			for i := 0; i < N; i++ {
+				key := rnd.Intn(N)
+
+				switch i % 5 {
+				case 0, 1:
+					cl.Set(key, i)
+
+				case 2:
+					cl.Remove(key)
+
+					// Keys owned by this worker can be removed by elements.
+					if elem := cl.Set(N*(int(seed)+1)+i, i); !elem.Remove() {
+						t.Errorf("element %v is not removed", elem.Key())
+					}
+
+				case 3:
+					cl.GetValue(key)
+
+				case 4:
+					prev := -1
+
+					for k := range cl.Between(key, key+100) {
+						if k.(int) <= prev {
+							t.Errorf("keys are not sorted: %v <= %v", k, prev)
+						}
+
+						prev = k.(int)
+					}
+				}
+			}
+		}(int64(w))
+	}
+
+	wg.Wait()
+
+	cl.View(func(list *SkipList) {
+		assertSanity(a, list)
+	})
+}
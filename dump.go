@@ -0,0 +1,302 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultDumpMaxElements is the default max count of elements rendered by WriteDOT and Dump.
+const DefaultDumpMaxElements = 32
+
+// DumpOptions is the options to render a list by WriteDOT.
+type DumpOptions struct {
+	// MaxElements is the max count of elements to render.
+	// Elements after that are rendered as a placeholder.
+	// If it's 0, DefaultDumpMaxElements is used.
+	// If it's negative, all elements are rendered.
+	MaxElements int
+
+	// SearchKey marks elements and levels visited when searching the key
+	// from the top level of the list header down to level 0.
+	// Unlike FindNext, the search doesn't compare the key with Front() and Back() first.
+	// Comparisons in the search are not counted in Stats.
+	// If it's nil, nothing is marked.
+	SearchKey interface{}
+}
+
+// searchTrace is the elements and levels visited by searching a key.
+type searchTrace struct {
+	nodes map[*elementHeader]bool
+	edges map[searchEdge]bool
+}
+
+type searchEdge struct {
+	from  *elementHeader
+	level int
+}
+
+// traceSearch returns elements and levels visited by findNext(nil, score, key).
+func (list *SkipList) traceSearch(key interface{}) (trace searchTrace) {
+	trace.nodes = map[*elementHeader]bool{}
+	trace.edges = map[searchEdge]bool{}
+
+	score := list.calcScore(key)
+	prevHeader := &list.elementHeader
+	trace.nodes[prevHeader] = true
+
+	for i := len(prevHeader.levels) - 1; i >= 0; i-- {
+		for next := prevHeader.levels[i]; next != nil; next = prevHeader.levels[i] {
+			comp := list.compareUncounted(score, key, next)
+
+			if comp == 0 && !list.duplicates {
+				trace.edges[searchEdge{prevHeader, i}] = true
+				trace.nodes[&next.elementHeader] = true
+				return
+			}
+
+			if comp <= 0 {
+				break
+			}
+
+			trace.edges[searchEdge{prevHeader, i}] = true
+			prevHeader = &next.elementHeader
+			trace.nodes[prevHeader] = true
+		}
+	}
+
+	if next := prevHeader.levels[0]; next != nil {
+		trace.edges[searchEdge{prevHeader, 0}] = true
+		trace.nodes[&next.elementHeader] = true
+	}
+
+	return
+}
+
+// dumpElements returns elements to render according to max.
+func (list *SkipList) dumpElements(max int) (elems []*Element) {
+	if max == 0 {
+		max = DefaultDumpMaxElements
+	}
+
+	for elem := list.Front(); elem != nil && (max < 0 || len(elems) < max); elem = elem.Next() {
+		elems = append(elems, elem)
+	}
+
+	return
+}
+
+// height returns the count of levels in use.
+// It's at least 1 to render an empty list.
+func (list *SkipList) height() int {
+	if list.level == 0 {
+		return 1
+	}
+
+	return list.level
+}
+
+// WriteDOT writes the structure of the list to w in Graphviz DOT format.
+// Every element is rendered as a record with all its levels, key and score.
+// Level pointers are solid edges, Prev() pointers are dashed edges
+// and pointers to the previous element on the top most level are dotted edges.
+// If opts.SearchKey is set, visited elements and levels are marked in red.
+//
+// The opts can be nil to use default options.
+func (list *SkipList) WriteDOT(w io.Writer, opts *DumpOptions) error {
+	if opts == nil {
+		opts = &DumpOptions{}
+	}
+
+	var trace searchTrace
+
+	if opts.SearchKey != nil {
+		trace = list.traceSearch(opts.SearchKey)
+	}
+
+	elems := list.dumpElements(opts.MaxElements)
+	ids := make(map[*Element]string, len(elems))
+
+	for i, elem := range elems {
+		ids[elem] = fmt.Sprintf("e%v", i)
+	}
+
+	id := func(elem *Element) string {
+		if elem == nil {
+			return "nil"
+		}
+
+		if id, ok := ids[elem]; ok {
+			return id
+		}
+
+		return "more"
+	}
+
+	buf := &bytes.Buffer{}
+	height := list.height()
+	nodeAttrs := func(header *elementHeader) string {
+		if trace.nodes[header] {
+			return ", color=red"
+		}
+
+		return ""
+	}
+	writeEdges := func(from string, header *elementHeader, level int) {
+		for i := level - 1; i >= 0; i-- {
+			attrs := ""
+
+			if trace.edges[searchEdge{header, i}] {
+				attrs = " [color=red, penwidth=2]"
+			}
+
+			fmt.Fprintf(buf, "\t%v:l%v -> %v%v;\n", from, i, id(header.levels[i]), attrs)
+		}
+	}
+
+	buf.WriteString("digraph skiplist {\n")
+	buf.WriteString("\trankdir=LR;\n")
+	buf.WriteString("\tnode [shape=record];\n")
+	fmt.Fprintf(buf, "\thead [label=\"%vhead|len %v\"%v];\n", dotLevels(height), list.length, nodeAttrs(&list.elementHeader))
+	buf.WriteString("\tnil [shape=plaintext];\n")
+
+	for _, elem := range elems {
+		fmt.Fprintf(buf, "\t%v [label=\"%v%v|%v\"%v];\n",
+			ids[elem], dotLevels(elem.Level()), dotEscape(fmt.Sprint(elem.key)), elem.score, nodeAttrs(&elem.elementHeader))
+	}
+
+	if len(elems) < list.length {
+		fmt.Fprintf(buf, "\tmore [shape=plaintext, label=\"... %v more elements\"];\n", list.length-len(elems))
+	}
+
+	writeEdges("head", &list.elementHeader, height)
+
+	for _, elem := range elems {
+		writeEdges(ids[elem], &elem.elementHeader, elem.Level())
+
+		if elem.prev != nil {
+			fmt.Fprintf(buf, "\t%v -> %v [style=dashed, color=gray, constraint=false];\n", ids[elem], id(elem.prev))
+		}
+
+		if elem.prevTopLevel != nil {
+			fmt.Fprintf(buf, "\t%v -> %v [style=dotted, color=blue, constraint=false];\n", ids[elem], id(elem.prevTopLevel))
+		}
+	}
+
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// dotLevels returns record fields for level pointers from top to bottom.
+func dotLevels(level int) string {
+	var sb strings.Builder
+
+	for i := level - 1; i >= 0; i-- {
+		fmt.Fprintf(&sb, "<l%v> L%v|", i, i)
+	}
+
+	return sb.String()
+}
+
+var dotEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`|`, `\|`,
+	`<`, `\<`,
+	`>`, `\>`,
+	"\n", `\n`,
+)
+
+func dotEscape(s string) string {
+	return dotEscaper.Replace(s)
+}
+
+// Dump writes the structure of the list to w as ASCII art.
+// Levels are rendered from top to bottom with keys and scores of all elements,
+// followed by the Prev() and PrevLevel(Level()-1) of every element.
+// At most DefaultDumpMaxElements elements are rendered.
+//
+// Here is a sample output.
+//
+//     L1 [head]->[1]----->[3]->nil
+//     L0 [head]->[1]->[2]->[3]->nil
+//     score       1    2    3
+//     #0 key=1 score=1 level=2 prev=nil prevTopLevel=nil
+//     #1 key=2 score=2 level=1 prev=1 prevTopLevel=1
+//     #2 key=3 score=3 level=2 prev=2 prevTopLevel=1
+func (list *SkipList) Dump(w io.Writer) error {
+	elems := list.dumpElements(DefaultDumpMaxElements)
+	height := list.height()
+	keys := make([]string, len(elems))
+	scores := make([]string, len(elems))
+	widths := make([]int, len(elems))
+
+	for i, elem := range elems {
+		keys[i] = fmt.Sprint(elem.key)
+		scores[i] = fmt.Sprint(elem.score)
+		widths[i] = max(len(keys[i]), len(scores[i]))
+	}
+
+	buf := &bytes.Buffer{}
+	labelWidth := len(fmt.Sprintf("L%v", height-1))
+	truncated := len(elems) < list.length
+
+	for i := height - 1; i >= 0; i-- {
+		fmt.Fprintf(buf, "%-*v [head]", labelWidth, fmt.Sprintf("L%v", i))
+		next := list.levels[i]
+
+		for j, elem := range elems {
+			if elem.Level() > i {
+				fmt.Fprintf(buf, "->[%-*v]", widths[j], keys[j])
+				next = elem.levels[i]
+			} else {
+				buf.WriteString(strings.Repeat("-", widths[j]+4))
+			}
+		}
+
+		if truncated && next != nil {
+			buf.WriteString("->...\n")
+		} else {
+			buf.WriteString("->nil\n")
+		}
+	}
+
+	if len(elems) != 0 {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%-*v", labelWidth+7, "score")
+
+		for j := range elems {
+			fmt.Fprintf(&sb, "   %-*v ", widths[j], scores[j])
+		}
+
+		buf.WriteString(strings.TrimRight(sb.String(), " "))
+		buf.WriteString("\n")
+	}
+
+	for j, elem := range elems {
+		fmt.Fprintf(buf, "#%v key=%v score=%v level=%v prev=%v prevTopLevel=%v\n",
+			j, keys[j], scores[j], elem.Level(), dumpKey(elem.prev), dumpKey(elem.prevTopLevel))
+	}
+
+	if truncated {
+		fmt.Fprintf(buf, "... %v more elements\n", list.length-len(elems))
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func dumpKey(elem *Element) string {
+	if elem == nil {
+		return "nil"
+	}
+
+	return fmt.Sprint(elem.key)
+}
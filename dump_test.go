@@ -0,0 +1,107 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func newDumpTestList(n int) *SkipList {
+	list := New(Int)
+
+	for i := 1; i <= n; i++ {
+		list.Set(i, i)
+	}
+
+	// Make levels predictable.
+	list.Rebalance()
+	return list
+}
+
+func TestDump(t *testing.T) {
+	a := assert.New(t)
+	buf := &bytes.Buffer{}
+
+	a.NilError(New(Int).Dump(buf))
+	a.Equal(buf.String(), "L0 [head]->nil\n")
+
+	buf.Reset()
+	a.NilError(newDumpTestList(4).Dump(buf))
+	a.Equal(buf.String(), strings.Join([]string{
+		"L2 [head]---------------->[4]->nil",
+		"L1 [head]------>[2]------>[4]->nil",
+		"L0 [head]->[1]->[2]->[3]->[4]->nil",
+		"score       1    2    3    4",
+		"#0 key=1 score=1 level=1 prev=nil prevTopLevel=nil",
+		"#1 key=2 score=2 level=2 prev=1 prevTopLevel=nil",
+		"#2 key=3 score=3 level=1 prev=2 prevTopLevel=2",
+		"#3 key=4 score=4 level=3 prev=3 prevTopLevel=nil",
+		"",
+	}, "\n"))
+
+	buf.Reset()
+	a.NilError(newDumpTestList(DefaultDumpMaxElements + 10).Dump(buf))
+	lines := strings.Split(buf.String(), "\n")
+	a.Assert(strings.HasPrefix(lines[0], "L5 [head]"))
+	a.Assert(strings.HasSuffix(lines[0], "->nil"))
+	a.Assert(strings.HasPrefix(lines[5], "L0 [head]"))
+	a.Assert(strings.HasSuffix(lines[5], "->..."))
+	a.Equal(lines[len(lines)-2], "... 10 more elements")
+}
+
+func TestWriteDOT(t *testing.T) {
+	a := assert.New(t)
+	buf := &bytes.Buffer{}
+
+	a.NilError(newDumpTestList(4).WriteDOT(buf, &DumpOptions{
+		MaxElements: 3,
+		SearchKey:   3,
+	}))
+	a.Equal(buf.String(), strings.Join([]string{
+		"digraph skiplist {",
+		"\trankdir=LR;",
+		"\tnode [shape=record];",
+		"\thead [label=\"<l2> L2|<l1> L1|<l0> L0|head|len 4\", color=red];",
+		"\tnil [shape=plaintext];",
+		"\te0 [label=\"<l0> L0|1|1\"];",
+		"\te1 [label=\"<l1> L1|<l0> L0|2|2\", color=red];",
+		"\te2 [label=\"<l0> L0|3|3\", color=red];",
+		"\tmore [shape=plaintext, label=\"... 1 more elements\"];",
+		"\thead:l2 -> more;",
+		"\thead:l1 -> e1 [color=red, penwidth=2];",
+		"\thead:l0 -> e0;",
+		"\te0:l0 -> e1;",
+		"\te1:l1 -> more;",
+		"\te1:l0 -> e2 [color=red, penwidth=2];",
+		"\te1 -> e0 [style=dashed, color=gray, constraint=false];",
+		"\te2:l0 -> more;",
+		"\te2 -> e1 [style=dashed, color=gray, constraint=false];",
+		"\te2 -> e1 [style=dotted, color=blue, constraint=false];",
+		"}",
+		"",
+	}, "\n"))
+
+	list := New(String)
+	list.Set(`a "quoted" {key} <with> |special|`, nil)
+	buf.Reset()
+	a.NilError(list.WriteDOT(buf, nil))
+	a.Assert(strings.Contains(buf.String(), `a \"quoted\" \{key\} \<with\> \|special\|`))
+}
+
+func TestWriteDOTStats(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{CountCompares: true})
+
+	for i := 1; i <= 100; i++ {
+		list.Set(i, i)
+	}
+
+	stats := list.Stats()
+	a.NilError(list.WriteDOT(&bytes.Buffer{}, &DumpOptions{SearchKey: 50}))
+	a.Equal(list.Stats(), stats)
+}
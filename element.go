@@ -24,6 +24,12 @@ type Element struct {
 // It must be the first anonymous field in a type to make Element() work correctly.
 type elementHeader struct {
 	levels []*Element // Next element at all levels.
+	spans  []int      // Number of elements skipped by following levels[i]. Only meaningful when levels[i] is not nil.
+
+	// Aggregates of elements skipped by following levels[i]. Only maintained if the list has an aggregator.
+	// Without an aggregator, it's always nil, which costs a slice header per element.
+	// It's kept here rather than in a separate allocation to avoid an indirection in Aggregate.
+	aggregates []interface{}
 }
 
 func (header *elementHeader) Element() *Element {
@@ -34,6 +40,7 @@ func newElement(list *SkipList, level int, score float64, key, value interface{}
 	return &Element{
 		elementHeader: elementHeader{
 			levels: make([]*Element, level),
+			spans:  make([]int, level),
 		},
 		Value: value,
 		key:   key,
@@ -106,6 +113,79 @@ func (elem *Element) Score() float64 {
 	return elem.score
 }
 
+// Index returns the 0-based position of this elem in its list.
+// If elem is not in any list, returns -1.
+//
+// The complexity is O(log(N)).
+func (elem *Element) Index() int {
+	list := elem.list
+
+	if list == nil {
+		return -1
+	}
+
+	rank := 0
+
+	// Walk back through previous elements pointing to top most levels.
+	// Every span on the way is added to the rank.
+	for e := elem; ; {
+		top := e.Level() - 1
+		prev := e.prevTopLevel
+
+		if prev == nil {
+			rank += list.spans[top]
+			break
+		}
+
+		rank += prev.spans[top]
+		e = prev
+	}
+
+	return rank - 1
+}
+
+// List returns the list containing this elem.
+// If elem has been removed from its list, returns nil.
+//
+// If the list is wrapped by a ConcurrentSkipList, returns nil as well,
+// as the list must not be accessed without the lock of the ConcurrentSkipList.
+func (elem *Element) List() *SkipList {
+	if list := elem.list; list != nil && list.owner == nil {
+		return list
+	}
+
+	return nil
+}
+
+// InList returns true if elem is in a list.
+// Removed elements are detached from their lists and cannot be added back.
+func (elem *Element) InList() bool {
+	return elem.list != nil
+}
+
+// Remove removes elem from its list.
+// Returns false if elem has been removed from its list before.
+//
+// If the list is wrapped by a ConcurrentSkipList, elem is removed under its write lock.
+// The elem must not be removed by another goroutine at the same time,
+// because elem's list is read before taking the lock.
+//
+// The complexity is O(log(N)).
+func (elem *Element) Remove() bool {
+	list := elem.list
+
+	if list == nil {
+		return false
+	}
+
+	if cl := list.owner; cl != nil {
+		return cl.removeElement(elem)
+	}
+
+	list.RemoveElement(elem)
+	return true
+}
+
 // Level returns the level of this elem.
 func (elem *Element) Level() int {
 	return len(elem.levels)
@@ -116,4 +196,6 @@ func (elem *Element) reset() {
 	elem.prev = nil
 	elem.prevTopLevel = nil
 	elem.levels = nil
+	elem.spans = nil
+	elem.aggregates = nil
 }
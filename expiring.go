@@ -0,0 +1,222 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"iter"
+	"time"
+)
+
+// lazySweepLimit is the max count of expired entries removed by every write to an ExpiringSkipList.
+const lazySweepLimit = 8
+
+// ExpiringSkipList is a skip list in which entries can expire after a TTL.
+//
+// Expired entries are invisible to Get, Find and All even if they are not removed yet.
+// They are removed lazily when they are found by Get or Find,
+// by every Set, SetWithTTL and Remove which removes a few expired entries in deadline order,
+// or by Sweep which removes all entries expired at a time.
+//
+// Deadlines are kept in a secondary skip list ordered by deadline,
+// so that expired entries can be found without walking through all entries.
+//
+// ExpiringSkipList is not goroutine-safe.
+type ExpiringSkipList struct {
+	list      *SkipList // Keys to *expiringEntry.
+	deadlines *SkipList // Deadlines in UnixNano to elements in list.
+	now       func() time.Time
+}
+
+// expiringEntry is the value of elements in ExpiringSkipList's list.
+type expiringEntry struct {
+	value    interface{}
+	deadline *Element // Element in the deadlines list. It's nil if the entry never expires.
+}
+
+// NewExpiring creates a new expiring skip list with comparable to compare keys.
+// The now returns current time to check whether an entry expires.
+// If now is nil, time.Now is used.
+func NewExpiring(comparable Comparable, now func() time.Time) *ExpiringSkipList {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &ExpiringSkipList{
+		list:      New(comparable),
+		deadlines: NewWithOptions(Int64, Options{AllowDuplicates: true}),
+		now:       now,
+	}
+}
+
+// Len returns the count of entries in the list.
+// Expired entries which are not removed yet are counted.
+func (el *ExpiringSkipList) Len() int {
+	return el.list.Len()
+}
+
+// Set sets value for the key. The entry never expires.
+// If the key exists with a TTL, the TTL is cleared.
+//
+// The complexity is O(log(N)).
+func (el *ExpiringSkipList) Set(key, value interface{}) {
+	el.set(key, value, nil)
+}
+
+// SetWithTTL sets value for the key. The entry expires after ttl since now.
+// If the key exists, both value and TTL are replaced.
+// If ttl is not positive, the entry expires immediately.
+//
+// The complexity is O(log(N)).
+func (el *ExpiringSkipList) SetWithTTL(key, value interface{}, ttl time.Duration) {
+	deadline := el.now().Add(ttl)
+	el.set(key, value, &deadline)
+}
+
+func (el *ExpiringSkipList) set(key, value interface{}, deadline *time.Time) {
+	el.sweep(el.now(), lazySweepLimit)
+
+	elem, loaded := el.list.GetOrSet(key, nil)
+	entry, _ := elem.Value.(*expiringEntry)
+
+	if !loaded {
+		entry = &expiringEntry{}
+		elem.Value = entry
+	}
+
+	entry.value = value
+
+	if entry.deadline != nil {
+		el.deadlines.RemoveElement(entry.deadline)
+		entry.deadline = nil
+	}
+
+	if deadline != nil {
+		entry.deadline = el.deadlines.Add(deadline.UnixNano(), elem)
+	}
+}
+
+// Get returns the value of the key.
+// If the key is not found or it's expired, returns nil and false.
+// An expired entry is removed.
+//
+// The complexity is O(log(N)).
+func (el *ExpiringSkipList) Get(key interface{}) (value interface{}, ok bool) {
+	elem := el.list.Get(key)
+
+	if elem == nil {
+		return
+	}
+
+	if el.expired(elem, el.now()) {
+		el.remove(elem)
+		return
+	}
+
+	value = elem.Value.(*expiringEntry).value
+	ok = true
+	return
+}
+
+// Find returns the first entry whose key is greater or equal to key and which is not expired.
+// If such entry doesn't exist, returns false as ok.
+// Expired entries visited are removed.
+//
+// The complexity is O((M+1)*log(N)), where M is the count of expired entries visited.
+func (el *ExpiringSkipList) Find(key interface{}) (foundKey, value interface{}, ok bool) {
+	now := el.now()
+	elem := el.list.Find(key)
+
+	for elem != nil && el.expired(elem, now) {
+		next := elem.Next()
+		el.remove(elem)
+		elem = next
+	}
+
+	if elem == nil {
+		return
+	}
+
+	foundKey = elem.Key()
+	value = elem.Value.(*expiringEntry).value
+	ok = true
+	return
+}
+
+// Remove removes the key and returns its value.
+// If the key is not found or it's expired, returns nil and false.
+//
+// The complexity is O(log(N)).
+func (el *ExpiringSkipList) Remove(key interface{}) (value interface{}, ok bool) {
+	now := el.now()
+	el.sweep(now, lazySweepLimit)
+	elem := el.list.Get(key)
+
+	if elem == nil {
+		return
+	}
+
+	if !el.expired(elem, now) {
+		value = elem.Value.(*expiringEntry).value
+		ok = true
+	}
+
+	el.remove(elem)
+	return
+}
+
+// All returns an iterator over keys and values of entries which are not expired from front to back.
+// Entries are checked against the time when iteration starts.
+// Expired entries are skipped but not removed.
+//
+// The list can be changed during iteration. See SkipList.All for details.
+func (el *ExpiringSkipList) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(key, value interface{}) bool) {
+		now := el.now()
+
+		el.list.forward(el.list.Front(), nil, func(elem *Element) bool {
+			if el.expired(elem, now) {
+				return true
+			}
+
+			return yield(elem.key, elem.Value.(*expiringEntry).value)
+		})
+	}
+}
+
+// Sweep removes all entries expired at now and returns the count of removed entries.
+//
+// The complexity is O(M*log(N)), where M is the count of removed entries.
+func (el *ExpiringSkipList) Sweep(now time.Time) int {
+	return el.sweep(now, -1)
+}
+
+// sweep removes at most limit entries expired at now in deadline order.
+// If limit is negative, all expired entries are removed.
+func (el *ExpiringSkipList) sweep(now time.Time, limit int) (removed int) {
+	deadline := now.UnixNano()
+
+	for front := el.deadlines.Front(); front != nil && removed != limit; front = el.deadlines.Front() {
+		if front.Key().(int64) > deadline {
+			break
+		}
+
+		el.remove(front.Value.(*Element))
+		removed++
+	}
+
+	return
+}
+
+func (el *ExpiringSkipList) expired(elem *Element, now time.Time) bool {
+	deadline := elem.Value.(*expiringEntry).deadline
+	return deadline != nil && deadline.Key().(int64) <= now.UnixNano()
+}
+
+func (el *ExpiringSkipList) remove(elem *Element) {
+	if deadline := elem.Value.(*expiringEntry).deadline; deadline != nil {
+		el.deadlines.RemoveElement(deadline)
+	}
+
+	el.list.RemoveElement(elem)
+}
@@ -0,0 +1,203 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/huandu/go-assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1000, 0)}
+}
+
+func expiringKeys(el *ExpiringSkipList) (keys []interface{}) {
+	for k := range el.All() {
+		keys = append(keys, k)
+	}
+
+	return
+}
+
+func TestExpiringSkipList(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	el := NewExpiring(Int, clock.Now)
+
+	el.Set(1, "one")
+	el.SetWithTTL(2, "two", time.Second)
+	el.SetWithTTL(3, "three", 3*time.Second)
+	el.SetWithTTL(4, "four", 2*time.Second)
+	a.Equal(el.Len(), 4)
+	a.Equal(expiringKeys(el), []interface{}{1, 2, 3, 4})
+
+	value, ok := el.Get(2)
+	a.Assert(ok)
+	a.Equal(value, "two")
+
+	// An entry expires exactly at its deadline.
+	clock.Advance(time.Second)
+	_, ok = el.Get(2)
+	a.Assert(!ok)
+	a.Equal(el.Len(), 3)
+	a.Equal(el.deadlines.Len(), 2)
+	a.Equal(expiringKeys(el), []interface{}{1, 3, 4})
+
+	clock.Advance(time.Second)
+	a.Equal(expiringKeys(el), []interface{}{1, 3})
+	a.Equal(el.Len(), 3)
+
+	k, v, ok := el.Find(2)
+	a.Assert(ok)
+	a.Equal(k, 3)
+	a.Equal(v, "three")
+	a.Equal(el.Len(), 3)
+
+	_, _, ok = el.Find(4)
+	a.Assert(!ok)
+	a.Equal(el.Len(), 2)
+
+	clock.Advance(time.Hour)
+	_, _, ok = el.Find(2)
+	a.Assert(!ok)
+	a.Equal(el.Len(), 1)
+
+	value, ok = el.Get(1)
+	a.Assert(ok)
+	a.Equal(value, "one")
+	a.Equal(el.deadlines.Len(), 0)
+	assertSanity(a, el.list)
+	assertSanity(a, el.deadlines)
+}
+
+func TestExpiringSkipListReplace(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	el := NewExpiring(Int, clock.Now)
+
+	el.SetWithTTL(1, "a", time.Second)
+	el.SetWithTTL(1, "b", time.Minute)
+	a.Equal(el.Len(), 1)
+	a.Equal(el.deadlines.Len(), 1)
+
+	clock.Advance(time.Second)
+	value, ok := el.Get(1)
+	a.Assert(ok)
+	a.Equal(value, "b")
+
+	// Set clears the TTL.
+	el.Set(1, "c")
+	a.Equal(el.deadlines.Len(), 0)
+	clock.Advance(time.Hour)
+	value, ok = el.Get(1)
+	a.Assert(ok)
+	a.Equal(value, "c")
+
+	el.SetWithTTL(1, "d", 0)
+	_, ok = el.Get(1)
+	a.Assert(!ok)
+	a.Equal(el.Len(), 0)
+
+	el.SetWithTTL(2, "e", time.Second)
+	value, ok = el.Remove(2)
+	a.Assert(ok)
+	a.Equal(value, "e")
+	a.Equal(el.Len(), 0)
+	a.Equal(el.deadlines.Len(), 0)
+
+	el.SetWithTTL(3, "f", time.Second)
+	clock.Advance(time.Second)
+	_, ok = el.Remove(3)
+	a.Assert(!ok)
+	a.Equal(el.Len(), 0)
+	a.Equal(el.deadlines.Len(), 0)
+}
+
+func TestExpiringSkipListSweep(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	el := NewExpiring(Int, clock.Now)
+	start := clock.Now()
+
+	// Same deadline for many keys.
+	for i := 0; i < 100; i++ {
+		el.SetWithTTL(i, i, time.Duration(i%10+1)*time.Second)
+	}
+
+	el.Set(1000, "forever")
+	a.Equal(el.Sweep(start), 0)
+	a.Equal(el.Sweep(start.Add(3*time.Second)), 30)
+	a.Equal(el.Len(), 71)
+
+	for k := range el.All() {
+		if k.(int) != 1000 {
+			a.Assert(k.(int)%10 >= 3)
+		}
+	}
+
+	a.Equal(el.Sweep(start.Add(time.Hour)), 70)
+	a.Equal(el.Len(), 1)
+	a.Equal(el.deadlines.Len(), 0)
+	assertSanity(a, el.list)
+}
+
+func TestExpiringSkipListLazySweep(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	el := NewExpiring(Int, clock.Now)
+
+	for i := 0; i < 100; i++ {
+		el.SetWithTTL(i, i, time.Second)
+	}
+
+	clock.Advance(time.Second)
+	a.Equal(el.Len(), 100)
+	a.Equal(len(expiringKeys(el)), 0)
+
+	// Every write removes a few expired entries.
+	el.Set(1000, "new")
+	a.Equal(el.Len(), 100-lazySweepLimit+1)
+
+	for i := 0; i < 100; i++ {
+		el.Set(2000+i, i)
+	}
+
+	a.Equal(el.Len(), 101)
+	a.Equal(el.deadlines.Len(), 0)
+}
+
+func TestExpiringSkipListIterationWithRemove(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	el := NewExpiring(Int, clock.Now)
+
+	for i := 0; i < 10; i++ {
+		el.SetWithTTL(i, i, time.Duration(i+1)*time.Second)
+	}
+
+	clock.Advance(5 * time.Second)
+	var keys []interface{}
+
+	for k := range el.All() {
+		keys = append(keys, k)
+		el.Remove(k)
+	}
+
+	a.Equal(keys, []interface{}{5, 6, 7, 8, 9})
+	a.Equal(el.Len(), 0)
+}
@@ -0,0 +1,234 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package generic
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestBasicCRUD(t *testing.T) {
+	a := assert.New(t)
+	list := NewOrdered[float64, string]()
+	a.Assert(list.Len() == 0)
+	a.Equal(list.Find(0), nil)
+
+	elem1 := list.Set(12.34, "first")
+	a.Assert(elem1 != nil)
+	a.Equal(list.Len(), 1)
+	a.Equal(list.Front(), elem1)
+	a.Equal(list.Back(), elem1)
+	a.Equal(elem1.Next(), nil)
+	a.Equal(elem1.Prev(), nil)
+	a.Equal(list.Find(0), elem1)
+	a.Equal(list.Find(12.34), elem1)
+	a.Equal(list.Find(15), nil)
+
+	assertSanity(a, list)
+
+	elem2 := list.Set(23.45, "second")
+	elem3 := list.Set(16.78, "middle")
+	elem4 := list.Set(9.01, "very beginning")
+	a.Equal(list.Len(), 4)
+	a.Equal(list.Front(), elem4)
+	a.Equal(list.Back(), elem2)
+	a.Equal(elem4.Next(), elem1)
+	a.Equal(elem3.Next(), elem2)
+	a.Equal(elem3.Prev(), elem1)
+	a.Equal(list.Find(15), elem3)
+	a.Equal(list.Find(20), elem2)
+	a.Equal(list.Find(25), nil)
+	a.Equal(list.FindNext(elem1, 15), elem3)
+	a.Equal(list.FindNext(elem3, 30), nil)
+
+	assertSanity(a, list)
+
+	elem5 := list.Set(16.78, "middle overwrite")
+	a.Equal(elem5, elem3)
+	a.Equal(list.Len(), 4)
+	a.Equal(elem5.Value, "middle overwrite")
+
+	v, ok := list.GetValue(23.45)
+	a.Assert(ok)
+	a.Equal(v, "second")
+	v, ok = list.GetValue(99)
+	a.Assert(!ok)
+	a.Equal(v, "")
+	a.Equal(list.MustGetValue(9.01), "very beginning")
+
+	a.Assert(list.Remove(9999) == nil)
+	a.Equal(list.Len(), 4)
+
+	list.SetMaxLevel(1)
+	assertSanity(a, list)
+	list.SetMaxLevel(128)
+	assertSanity(a, list)
+
+	elem2Removed := list.Remove(elem2.Key())
+	a.Equal(elem2Removed, elem2)
+	a.Assert(elem2Removed.Next() == nil)
+	a.Equal(list.Len(), 3)
+	a.Equal(list.Back(), elem5)
+
+	assertSanity(a, list)
+
+	a.Equal(list.RemoveFront(), elem4)
+	a.Equal(list.RemoveBack(), elem5)
+	a.Equal(list.Len(), 1)
+	a.Equal(list.Front(), elem1)
+	a.Equal(list.Back(), elem1)
+
+	assertSanity(a, list)
+
+	list.Init()
+	a.Equal(list.Len(), 0)
+	a.Equal(list.Get(12.34), nil)
+}
+
+func TestCustomCompare(t *testing.T) {
+	a := assert.New(t)
+	list := New[string, int](func(lhs, rhs string) int {
+		return strings.Compare(rhs, lhs)
+	})
+	list.Set("a", 1)
+	list.Set("c", 3)
+	list.Set("b", 2)
+
+	a.Equal(list.Front().Key(), "c")
+	a.Equal(list.Back().Key(), "a")
+	a.Equal(list.Find("bb").Key(), "b")
+	a.Equal(list.Get("b").Value, 2)
+
+	assertSanity(a, list)
+}
+
+func TestRandomList(t *testing.T) {
+	a := assert.New(t)
+
+	const seed = 0xa30378d2
+	const N = 100000
+	rnd := rand.New(rand.NewSource(seed))
+	list := NewOrdered[int, int]()
+
+	for i := 0; i < N; i++ {
+		key := rnd.Intn(N)
+		list.Set(key, i)
+	}
+
+	for i := 0; i < N; i++ {
+		switch i % 4 {
+		case 0:
+			list.Remove(rnd.Intn(N))
+
+		case 1:
+			list.Set(rnd.Intn(N), i)
+
+		case 2:
+			list.RemoveBack()
+
+		case 3:
+			list.RemoveFront()
+		}
+	}
+
+	assertSanity(a, list)
+}
+
+func BenchmarkRandomSelect(b *testing.B) {
+	list := NewOrdered[int, int]()
+	keys := make([]int, 0, b.N)
+
+	for i := 0; i < b.N; i++ {
+		keys = append(keys, i)
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	rnd.Shuffle(b.N, func(i, j int) {
+		keys[i], keys[j] = keys[j], keys[i]
+	})
+
+	for i := 0; i < b.N; i++ {
+		list.Set(keys[i], i)
+	}
+
+	rnd.Shuffle(b.N, func(i, j int) {
+		keys[i], keys[j] = keys[j], keys[i]
+	})
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		list.Get(keys[i])
+	}
+}
+
+func ExampleSkipList() {
+	// Create a skip list with int key and string value.
+	list := NewOrdered[int, string]()
+
+	list.Set(12, "hello world")
+	list.Set(34, "foo")
+	list.Set(78, "bar")
+
+	// Value is a string. No type assertion is needed.
+	elem := list.Get(34)
+	fmt.Println(elem.Value)
+
+	val, ok := list.GetValue(78)
+	fmt.Println(val, ok)
+
+	// Output:
+	// foo
+	// bar true
+}
+
+func assertSanity[K, V any](a *assert.A, list *SkipList[K, V]) {
+	l := list.Len()
+	cnt := 0
+	a.Use(&l, &cnt)
+
+	if l == 0 {
+		return
+	}
+
+	allElems := make([]*Element[K, V], 0, l)
+
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		allElems = append(allElems, elem)
+		cnt++
+
+		a.Assert(elem.list == list)
+	}
+
+	a.Assert(cnt == l)
+	a.Equal(allElems[0], list.Front())
+	a.Equal(allElems[l-1], list.Back())
+
+	for i := 1; i < l; i++ {
+		a.Assert(list.compare(allElems[i-1].Key(), allElems[i].Key()) < 0)
+	}
+
+	for i := 0; i < len(list.levels); i++ {
+		var prev *Element[K, V]
+		elem := list.levels[i]
+
+		for elem != nil {
+			a.Assert(elem.Level() > i)
+			a.Equal(elem.PrevLevel(i), prev)
+
+			prev = elem
+			elem = elem.NextLevel(i)
+		}
+	}
+
+	for _, elem := range allElems {
+		if prev := elem.Prev(); prev != nil {
+			a.Equal(prev.Next(), elem)
+		}
+	}
+}
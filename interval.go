@@ -0,0 +1,170 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+// Interval is a closed interval [Lo, Hi] with a value in an IntervalSkipList.
+type Interval struct {
+	Lo    interface{}
+	Hi    interface{}
+	Value interface{}
+
+	elem *Element
+}
+
+// IntervalSkipList is a skip list of closed intervals.
+// It finds all intervals containing a point or overlapping a range
+// in time proportional to the count of found intervals.
+//
+// Intervals are sorted by Lo. Every level pointer caches the max Hi of
+// intervals it skips, so that intervals ending before a query are skipped altogether.
+// Intervals with the same endpoints are allowed.
+//
+// IntervalSkipList is not goroutine-safe.
+type IntervalSkipList struct {
+	comparable Comparable
+	list       *SkipList
+}
+
+// NewInterval creates a new interval skip list with comparable to compare endpoints.
+func NewInterval(comparable Comparable) *IntervalSkipList {
+	il := &IntervalSkipList{
+		comparable: comparable,
+		list:       NewWithOptions(comparable, Options{AllowDuplicates: true}),
+	}
+	il.list.setAggregator(&aggregator{
+		combine: il.maxHi,
+		lift: func(elem *Element) interface{} {
+			return elem.Value.(*Interval).Hi
+		},
+	})
+	return il
+}
+
+// maxHi returns the greater one of lhs and rhs. A nil is less than any endpoint.
+func (il *IntervalSkipList) maxHi(lhs, rhs interface{}) interface{} {
+	if lhs == nil {
+		return rhs
+	}
+
+	if rhs == nil || il.comparable.Compare(lhs, rhs) >= 0 {
+		return lhs
+	}
+
+	return rhs
+}
+
+// Len returns the count of intervals.
+func (il *IntervalSkipList) Len() int {
+	return il.list.Len()
+}
+
+// Insert adds the interval [lo, hi] with value and returns it.
+// If lo is greater than hi, nothing is added and returns nil.
+//
+// The complexity is O(log(N)).
+func (il *IntervalSkipList) Insert(lo, hi, value interface{}) *Interval {
+	if il.comparable.Compare(lo, hi) > 0 {
+		return nil
+	}
+
+	iv := &Interval{
+		Lo:    lo,
+		Hi:    hi,
+		Value: value,
+	}
+	iv.elem = il.list.Add(lo, iv)
+	return iv
+}
+
+// Remove removes the interval returned by Insert.
+// Returns false if the interval is not in the list.
+//
+// The complexity is O(log(N)).
+func (il *IntervalSkipList) Remove(iv *Interval) bool {
+	if iv == nil || iv.elem == nil || iv.elem.list != il.list {
+		return false
+	}
+
+	il.list.RemoveElement(iv.elem)
+	iv.elem = nil
+	return true
+}
+
+// Stab returns all intervals containing point sorted by Lo.
+//
+// The complexity is O((K+1)*log(N)), where K is the count of returned intervals.
+func (il *IntervalSkipList) Stab(point interface{}) []*Interval {
+	return il.Overlapping(point, point)
+}
+
+// Overlapping returns all intervals overlapping [lo, hi] sorted by Lo.
+// Intervals with an endpoint equal to lo or hi are included.
+//
+// The complexity is O((K+1)*log(N)), where K is the count of returned intervals.
+func (il *IntervalSkipList) Overlapping(lo, hi interface{}) []*Interval {
+	list := il.list
+	top := len(list.levels) - 1
+	header := &list.elementHeader
+	q := &intervalQuery{
+		lo:      lo,
+		hi:      hi,
+		hiScore: list.calcScore(hi),
+	}
+
+	for il.collect(header, top, q) {
+		next := header.levels[top]
+
+		if next == nil {
+			break
+		}
+
+		header = &next.elementHeader
+	}
+
+	return q.intervals
+}
+
+// intervalQuery is the query and result of IntervalSkipList.Overlapping.
+type intervalQuery struct {
+	lo, hi    interface{}
+	hiScore   float64
+	intervals []*Interval
+}
+
+// collect appends intervals overlapping the query among intervals skipped by
+// following header.levels[i] to q.intervals.
+// Returns false if there is no more interval starting before q.hi.
+func (il *IntervalSkipList) collect(header *elementHeader, i int, q *intervalQuery) bool {
+	// Intervals are sorted by Lo. Stop when the first one starts after hi.
+	if first := header.levels[0]; first == nil || il.list.compare(q.hiScore, q.hi, first) < 0 {
+		return false
+	}
+
+	next := header.levels[i]
+
+	// Skip all intervals ending before lo.
+	// The max Hi is only cached on levels pointing to an element.
+	if next != nil && il.comparable.Compare(header.aggregates[i], q.lo) < 0 {
+		return true
+	}
+
+	if i == 0 {
+		q.intervals = append(q.intervals, next.Value.(*Interval))
+		return true
+	}
+
+	for {
+		if !il.collect(header, i-1, q) {
+			return false
+		}
+
+		elem := header.levels[i-1]
+
+		if elem == nil || elem == next {
+			return true
+		}
+
+		header = &elem.elementHeader
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func bruteForceOverlapping(intervals []*Interval, lo, hi int) (result []*Interval) {
+	for _, iv := range intervals {
+		if iv.Lo.(int) <= hi && iv.Hi.(int) >= lo {
+			result = append(result, iv)
+		}
+	}
+
+	return
+}
+
+func TestIntervalSkipList(t *testing.T) {
+	a := assert.New(t)
+	il := NewInterval(Int)
+
+	a.Equal(il.Stab(1), []*Interval(nil))
+	a.Assert(il.Insert(5, 1, "bad") == nil)
+
+	iv1 := il.Insert(1, 5, "a")
+	iv2 := il.Insert(3, 8, "b")
+	iv3 := il.Insert(6, 7, "c")
+	iv4 := il.Insert(10, 10, "d")
+	iv5 := il.Insert(1, 5, "e")
+	a.Equal(il.Len(), 5)
+
+	a.Equal(il.Stab(0), []*Interval(nil))
+	a.Equal(il.Stab(1), []*Interval{iv1, iv5})
+	a.Equal(il.Stab(5), []*Interval{iv1, iv5, iv2})
+	a.Equal(il.Stab(6), []*Interval{iv2, iv3})
+	a.Equal(il.Stab(9), []*Interval(nil))
+	a.Equal(il.Stab(10), []*Interval{iv4})
+	a.Equal(il.Overlapping(8, 10), []*Interval{iv2, iv4})
+	a.Equal(il.Overlapping(-100, 100), []*Interval{iv1, iv5, iv2, iv3, iv4})
+
+	a.Assert(il.Remove(iv2))
+	a.Assert(!il.Remove(iv2))
+	a.Assert(!il.Remove(nil))
+	a.Equal(il.Len(), 4)
+	a.Equal(il.Stab(5), []*Interval{iv1, iv5})
+	a.Equal(il.Overlapping(8, 10), []*Interval{iv4})
+	assertAggregates(a, il.list)
+}
+
+func TestIntervalSkipListRandom(t *testing.T) {
+	a := assert.New(t)
+	il := NewInterval(Int)
+	var intervals []*Interval
+
+	for i := 0; i < 500; i++ {
+		lo := rand.Intn(1000)
+		hi := lo + rand.Intn(50)
+		intervals = append(intervals, il.Insert(lo, hi, i))
+	}
+
+	assertAggregates(a, il.list)
+
+	// Remove some intervals randomly.
+	rand.Shuffle(len(intervals), func(i, j int) {
+		intervals[i], intervals[j] = intervals[j], intervals[i]
+	})
+
+	for _, iv := range intervals[:200] {
+		a.Assert(il.Remove(iv))
+	}
+
+	intervals = intervals[200:]
+	a.Equal(il.Len(), len(intervals))
+	assertAggregates(a, il.list)
+	assertSanity(a, il.list)
+
+	// Keep the same order as the list.
+	sorted := make([]*Interval, 0, len(intervals))
+
+	for _, v := range il.list.All() {
+		sorted = append(sorted, v.(*Interval))
+	}
+
+	for i := 0; i < 200; i++ {
+		lo := rand.Intn(1100) - 50
+		hi := lo + rand.Intn(20)
+
+		a.Equal(il.Overlapping(lo, hi), bruteForceOverlapping(sorted, lo, hi))
+		a.Equal(il.Stab(lo), bruteForceOverlapping(sorted, lo, lo))
+	}
+}
+
+func BenchmarkIntervalSkipListStab(b *testing.B) {
+	il := NewInterval(Int)
+
+	for i := 0; i < 100000; i++ {
+		lo := rand.Intn(10000000)
+		il.Insert(lo, lo+rand.Intn(1000), i)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		il.Stab(rand.Intn(10000000))
+	}
+}
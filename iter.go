@@ -0,0 +1,135 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"iter"
+)
+
+// All returns an iterator over keys and values of all elements from front to back.
+//
+// The list can be changed during iteration.
+// After yielding a key, the iteration always continues with the first element
+// whose key is greater than the yielded key at that moment.
+// It's safe to remove or rekey the element being yielded or any other element.
+// Elements set after the yielded key will be yielded.
+// If duplicates are allowed, elements after the yielded one with the same key
+// are yielded before any greater key.
+func (list *SkipList) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(key, value interface{}) bool) {
+		list.forward(list.Front(), nil, func(elem *Element) bool {
+			return yield(elem.key, elem.Value)
+		})
+	}
+}
+
+// Backward returns an iterator over keys and values of all elements from back to front.
+//
+// The list can be changed during iteration.
+// After yielding a key, the iteration always continues with the last element
+// whose key is less than the yielded key at that moment.
+// It's safe to remove or rekey the element being yielded or any other element.
+// Elements set before the yielded key will be yielded.
+// If duplicates are allowed, elements before the yielded one with the same key
+// are yielded before any less key.
+func (list *SkipList) Backward() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(key, value interface{}) bool) {
+		elem := list.Back()
+
+		for elem != nil {
+			// The elem may be rekeyed by yield. Remember the yielded key.
+			key, score := elem.key, elem.score
+			prev := elem.prev
+
+			if !yield(key, elem.Value) {
+				return
+			}
+
+			if elem.list == list && list.compare(score, key, elem) == 0 {
+				elem = elem.prev
+				continue
+			}
+
+			// The elem is removed or rekeyed. Continue with previous element with the same key.
+			if list.duplicates && prev != nil && prev.list == list && list.compare(score, key, prev) == 0 {
+				elem = prev
+				continue
+			}
+
+			// Seek the last element less than the yielded key.
+			elem = list.findPrev(score, key, false)
+		}
+	}
+}
+
+// Keys returns an iterator over keys of all elements from front to back.
+// See All for behavior when the list is changed during iteration.
+func (list *SkipList) Keys() iter.Seq[interface{}] {
+	return func(yield func(key interface{}) bool) {
+		list.forward(list.Front(), nil, func(elem *Element) bool {
+			return yield(elem.key)
+		})
+	}
+}
+
+// Values returns an iterator over values of all elements from front to back.
+// See All for behavior when the list is changed during iteration.
+func (list *SkipList) Values() iter.Seq[interface{}] {
+	return func(yield func(value interface{}) bool) {
+		list.forward(list.Front(), nil, func(elem *Element) bool {
+			return yield(elem.Value)
+		})
+	}
+}
+
+// Between returns an iterator over keys and values of elements
+// with keys in the closed range [lo, hi] from front to back.
+// Use Range for exclusive bounds, offset and limit.
+// See All for behavior when the list is changed during iteration.
+//
+// The complexity is O(log(N)) to seek the first element.
+func (list *SkipList) Between(lo, hi interface{}) iter.Seq2[interface{}, interface{}] {
+	return func(yield func(key, value interface{}) bool) {
+		hiScore := list.calcScore(hi)
+		stop := func(elem *Element) bool {
+			return list.compare(hiScore, hi, elem) < 0
+		}
+
+		list.forward(list.FindNext(nil, lo), stop, func(elem *Element) bool {
+			return yield(elem.key, elem.Value)
+		})
+	}
+}
+
+// forward calls yield with elements from elem to back until yield returns false
+// or stop returns true.
+func (list *SkipList) forward(elem *Element, stop func(elem *Element) bool, yield func(elem *Element) bool) {
+	for elem != nil {
+		if stop != nil && stop(elem) {
+			return
+		}
+
+		// The elem may be rekeyed by yield. Remember the yielded key.
+		key, score := elem.key, elem.score
+		next := elem.Next()
+
+		if !yield(elem) {
+			return
+		}
+
+		if elem.list == list && list.compare(score, key, elem) == 0 {
+			elem = elem.Next()
+			continue
+		}
+
+		// The elem is removed or rekeyed. Continue with next element with the same key.
+		if list.duplicates && next != nil && next.list == list && list.compare(score, key, next) == 0 {
+			elem = next
+			continue
+		}
+
+		// Seek the first element greater than the yielded key.
+		elem = list.higher(score, key)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestIterators(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	for range list.All() {
+		t.Fatal("empty list must not yield anything")
+	}
+
+	for i := 0; i < 5; i++ {
+		list.Set(i, fmt.Sprint("v", i))
+	}
+
+	keys := []interface{}{}
+	values := []interface{}{}
+
+	for k, v := range list.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	a.Equal(keys, []interface{}{0, 1, 2, 3, 4})
+	a.Equal(values, []interface{}{"v0", "v1", "v2", "v3", "v4"})
+
+	keys = keys[:0]
+
+	for k := range list.Backward() {
+		keys = append(keys, k)
+	}
+
+	a.Equal(keys, []interface{}{4, 3, 2, 1, 0})
+
+	keys = keys[:0]
+
+	for k := range list.Keys() {
+		keys = append(keys, k)
+	}
+
+	a.Equal(keys, []interface{}{0, 1, 2, 3, 4})
+
+	values = values[:0]
+
+	for v := range list.Values() {
+		values = append(values, v)
+	}
+
+	a.Equal(values, []interface{}{"v0", "v1", "v2", "v3", "v4"})
+
+	keys = keys[:0]
+
+	for k := range list.Between(1, 3) {
+		keys = append(keys, k)
+	}
+
+	a.Equal(keys, []interface{}{1, 2, 3})
+
+	keys = keys[:0]
+
+	for k := range list.Between(-10, 10) {
+		keys = append(keys, k)
+
+		if k == 2 {
+			break
+		}
+	}
+
+	a.Equal(keys, []interface{}{0, 1, 2})
+
+	for range list.Between(3, 1) {
+		t.Fatal("empty range must not yield anything")
+	}
+}
+
+func TestIteratorsWithModification(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	for i := 0; i < 10; i++ {
+		list.Set(i*10, i)
+	}
+
+	// Remove all yielded elements and add new elements ahead.
+	keys := []interface{}{}
+
+	for k := range list.All() {
+		keys = append(keys, k)
+		list.Remove(k)
+
+		if k == 20 {
+			list.Set(25, 0)
+			list.Remove(30)
+		}
+	}
+
+	a.Equal(keys, []interface{}{0, 10, 20, 25, 40, 50, 60, 70, 80, 90})
+	a.Equal(list.Len(), 0)
+
+	for i := 0; i < 10; i++ {
+		list.Set(i*10, i)
+	}
+
+	keys = keys[:0]
+
+	for k := range list.Backward() {
+		keys = append(keys, k)
+		list.Remove(k)
+
+		if k == 70 {
+			list.Set(65, 0)
+		}
+	}
+
+	a.Equal(keys, []interface{}{90, 80, 70, 65, 60, 50, 40, 30, 20, 10, 0})
+
+	// Removed element can be set again during iteration.
+	for i := 0; i < 10; i++ {
+		list.Set(i*10, i)
+	}
+
+	keys = keys[:0]
+
+	for k := range list.Keys() {
+		keys = append(keys, k)
+
+		if k == 30 {
+			list.Remove(30)
+			list.Remove(40)
+			list.Set(30, "again")
+		}
+	}
+
+	a.Equal(keys, []interface{}{0, 10, 20, 30, 50, 60, 70, 80, 90})
+
+	keys = keys[:0]
+
+	for k := range list.Backward() {
+		keys = append(keys, k)
+
+		if k == 30 {
+			list.Remove(30)
+			list.Remove(20)
+			list.Set(30, "again")
+		}
+	}
+
+	a.Equal(keys, []interface{}{90, 80, 70, 60, 50, 30, 10, 0})
+}
+
+func TestIteratorsWithRekey(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	for i := 1; i <= 5; i++ {
+		list.Set(i, i)
+	}
+
+	// Rekeyed element is yielded again at its new position.
+	keys := []interface{}{}
+
+	for k := range list.All() {
+		keys = append(keys, k)
+
+		if k == 1 {
+			a.Assert(list.Rekey(list.Get(1), 10))
+		}
+	}
+
+	a.Equal(keys, []interface{}{1, 2, 3, 4, 5, 10})
+
+	keys = keys[:0]
+
+	for k := range list.Backward() {
+		keys = append(keys, k)
+
+		if k == 10 {
+			a.Assert(list.Rekey(list.Get(10), 0))
+		}
+	}
+
+	a.Equal(keys, []interface{}{10, 5, 4, 3, 2, 0})
+	assertSanity(a, list)
+
+	// Rekeyed element with duplicates.
+	dup := NewWithOptions(Int, Options{AllowDuplicates: true})
+	dup.Add(1, "a")
+	dup.Add(1, "b")
+	dup.Add(2, "c")
+	values := []interface{}{}
+
+	for k, v := range dup.All() {
+		values = append(values, v)
+
+		if k == 1 && v == "a" {
+			a.Assert(dup.Rekey(dup.Front(), 3))
+		}
+	}
+
+	a.Equal(values, []interface{}{"a", "b", "c", "a"})
+}
+
+func ExampleSkipList_All() {
+	list := New(Int)
+	list.Set(2, "b")
+	list.Set(1, "a")
+	list.Set(3, "c")
+
+	for k, v := range list.All() {
+		fmt.Println(k, v)
+	}
+
+	// Output:
+	// 1 a
+	// 2 b
+	// 3 c
+}
@@ -0,0 +1,381 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"iter"
+	"math/rand"
+	"sync/atomic"
+)
+
+// LockFreeSkipList is a skip list which can be read and written by any number of goroutines
+// without any lock.
+//
+// It's implemented with atomic next pointers and logical deletion marks
+// as described in "The Art of Multiprocessor Programming" by Herlihy and Shavit,
+// which is based on the work of Harris and Fraser.
+// An element is removed logically by marking its next pointers first,
+// and then it's unlinked physically by any goroutine walking through it.
+// The value of an element is swapped to a tombstone before marking level 0,
+// so that a value is either removed or set, but never both.
+//
+// Unlike SkipList, there is no *Element exposed, as an element can be removed
+// by other goroutines at any time.
+type LockFreeSkipList struct {
+	head       *lockFreeNode
+	comparable Comparable
+	maxLevel   int
+	length     atomic.Int64
+}
+
+// lockFreeRef is an immutable markable reference to the next node.
+// It's always replaced as a whole by compare-and-swap.
+type lockFreeRef struct {
+	node   *lockFreeNode
+	marked bool // The node owning this ref is logically removed.
+}
+
+type lockFreeNode struct {
+	key   interface{}
+	score float64
+	value atomic.Pointer[interface{}]
+	next  []atomic.Pointer[lockFreeRef]
+}
+
+// lockFreeTombstone is the value of a removed node.
+// The goroutine swapping a node's value to it removes the node.
+var lockFreeTombstone = new(interface{})
+
+func newLockFreeNode(level int, score float64, key, value interface{}) *lockFreeNode {
+	node := &lockFreeNode{
+		key:   key,
+		score: score,
+		next:  make([]atomic.Pointer[lockFreeRef], level),
+	}
+	node.value.Store(&value)
+	return node
+}
+
+// NewLockFree creates a new lock-free skip list with comparable to compare keys.
+//
+// The max level is DefaultMaxLevel and it cannot be changed after creation.
+func NewLockFree(comparable Comparable) *LockFreeSkipList {
+	if DefaultMaxLevel <= 0 {
+		panic("skiplist default level must not be zero or negative")
+	}
+
+	head := newLockFreeNode(DefaultMaxLevel, 0, nil, nil)
+
+	for i := range head.next {
+		head.next[i].Store(&lockFreeRef{})
+	}
+
+	return &LockFreeSkipList{
+		head:       head,
+		comparable: comparable,
+		maxLevel:   DefaultMaxLevel,
+	}
+}
+
+// Len returns element count in this list.
+// The count may be stale if the list is being changed by other goroutines.
+//
+// The complexity is O(1).
+func (list *LockFreeSkipList) Len() int {
+	return int(list.length.Load())
+}
+
+// Set sets value for the key.
+// If the key exists, updates element's value and returns false.
+// Otherwise, inserts a new element and returns true.
+//
+// The complexity is O(log(N)).
+func (list *LockFreeSkipList) Set(key, value interface{}) (inserted bool) {
+	score := list.comparable.CalcScore(key)
+	level := list.randLevel()
+	preds := make([]*lockFreeNode, list.maxLevel)
+	predRefs := make([]*lockFreeRef, list.maxLevel)
+	succs := make([]*lockFreeNode, list.maxLevel)
+
+	for {
+		if list.find(score, key, preds, predRefs, succs) {
+			if succs[0].swapValue(&value) {
+				return
+			}
+
+			// The node is being removed. Help to mark it and insert a new one.
+			succs[0].mark(0)
+			continue
+		}
+
+		node := newLockFreeNode(level, score, key, value)
+
+		for i := 0; i < level; i++ {
+			node.next[i].Store(&lockFreeRef{node: succs[i]})
+		}
+
+		// The node is in the list once it's linked at level 0.
+		if !casNext(&preds[0].next[0], predRefs[0], node) {
+			continue
+		}
+
+		list.length.Add(1)
+		inserted = true
+
+		for i := 1; i < level; i++ {
+			for {
+				// Point to the latest successor unless the node is being removed.
+				ref := node.next[i].Load()
+
+				if ref.marked {
+					return
+				}
+
+				if ref.node != succs[i] && !node.next[i].CompareAndSwap(ref, &lockFreeRef{node: succs[i]}) {
+					continue
+				}
+
+				if casNext(&preds[i].next[i], predRefs[i], node) {
+					// The node may be marked after it's unlinked by Remove. Unlink it again.
+					if node.next[i].Load().marked {
+						list.find(score, key, preds, predRefs, succs)
+						return
+					}
+
+					break
+				}
+
+				list.find(score, key, preds, predRefs, succs)
+			}
+		}
+
+		return
+	}
+}
+
+// Get returns value of the element with the key.
+// If the key is not found, ok is false.
+//
+// The complexity is O(log(N)).
+func (list *LockFreeSkipList) Get(key interface{}) (value interface{}, ok bool) {
+	score := list.comparable.CalcScore(key)
+	pred := list.head
+	var curr *lockFreeNode
+
+	for i := list.maxLevel - 1; i >= 0; i-- {
+		curr = pred.next[i].Load().node
+
+		for curr != nil {
+			ref := curr.next[i].Load()
+
+			// Skip logically removed nodes without unlinking them.
+			for ref.marked {
+				curr = ref.node
+
+				if curr == nil {
+					break
+				}
+
+				ref = curr.next[i].Load()
+			}
+
+			if curr == nil || list.compare(score, key, curr) <= 0 {
+				break
+			}
+
+			pred = curr
+			curr = ref.node
+		}
+	}
+
+	if curr == nil || list.compare(score, key, curr) != 0 || curr.next[0].Load().marked {
+		return
+	}
+
+	ptr := curr.value.Load()
+
+	if ptr == lockFreeTombstone {
+		return
+	}
+
+	value = *ptr
+	ok = true
+	return
+}
+
+// Remove removes the element with the key.
+// Returns the value of removed element and true if found.
+// If the key is not found or it's removed by another goroutine, ok is false.
+//
+// The complexity is O(log(N)).
+func (list *LockFreeSkipList) Remove(key interface{}) (value interface{}, ok bool) {
+	score := list.comparable.CalcScore(key)
+	preds := make([]*lockFreeNode, list.maxLevel)
+	predRefs := make([]*lockFreeRef, list.maxLevel)
+	succs := make([]*lockFreeNode, list.maxLevel)
+
+	if !list.find(score, key, preds, predRefs, succs) {
+		return
+	}
+
+	node := succs[0]
+
+	// Mark all levels except level 0 from top to bottom.
+	for i := len(node.next) - 1; i > 0; i-- {
+		node.mark(i)
+	}
+
+	// The goroutine swapping the value to the tombstone wins.
+	// Set cannot change the value any more after that.
+	ptr := node.value.Load()
+
+	for ; ptr != lockFreeTombstone; ptr = node.value.Load() {
+		if node.value.CompareAndSwap(ptr, lockFreeTombstone) {
+			break
+		}
+	}
+
+	if ptr == lockFreeTombstone {
+		return
+	}
+
+	node.mark(0)
+	list.length.Add(-1)
+	value = *ptr
+	ok = true
+
+	// Unlink the node physically.
+	list.find(score, key, preds, predRefs, succs)
+	return
+}
+
+// swapValue sets value of the node unless the node is removed.
+// Returns false if the node is removed.
+func (node *lockFreeNode) swapValue(value *interface{}) bool {
+	for {
+		ptr := node.value.Load()
+
+		if ptr == lockFreeTombstone {
+			return false
+		}
+
+		if node.value.CompareAndSwap(ptr, value) {
+			return true
+		}
+	}
+}
+
+// mark marks the next pointer on level i to remove the node logically.
+func (node *lockFreeNode) mark(i int) {
+	for ref := node.next[i].Load(); !ref.marked; ref = node.next[i].Load() {
+		node.next[i].CompareAndSwap(ref, &lockFreeRef{node: ref.node, marked: true})
+	}
+}
+
+// All returns an iterator over keys and values of all elements from front to back.
+//
+// The iteration is weakly consistent.
+// It's safe to change the list during iteration in any goroutine.
+// Elements removed before being visited are not yielded.
+// Elements set after the position being visited may or may not be yielded.
+func (list *LockFreeSkipList) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(key, value interface{}) bool) {
+		for curr := list.head.next[0].Load().node; curr != nil; {
+			ref := curr.next[0].Load()
+
+			if ptr := curr.value.Load(); !ref.marked && ptr != lockFreeTombstone && !yield(curr.key, *ptr) {
+				return
+			}
+
+			curr = ref.node
+		}
+	}
+}
+
+// find finds out predecessors and successors of key on all levels.
+// Logically removed nodes on the way are unlinked.
+// Returns true if succs[0] holds the key.
+func (list *LockFreeSkipList) find(score float64, key interface{}, preds []*lockFreeNode, predRefs []*lockFreeRef, succs []*lockFreeNode) bool {
+retry:
+	for {
+		pred := list.head
+		var curr *lockFreeNode
+
+		for i := list.maxLevel - 1; i >= 0; i-- {
+			predRef := pred.next[i].Load()
+			curr = predRef.node
+
+			for curr != nil {
+				ref := curr.next[i].Load()
+
+				for ref.marked {
+					snip := &lockFreeRef{node: ref.node}
+
+					if predRef.marked || !pred.next[i].CompareAndSwap(predRef, snip) {
+						continue retry
+					}
+
+					predRef = snip
+					curr = ref.node
+
+					if curr == nil {
+						break
+					}
+
+					ref = curr.next[i].Load()
+				}
+
+				if curr == nil || list.compare(score, key, curr) <= 0 {
+					break
+				}
+
+				pred = curr
+				predRef = ref
+				curr = ref.node
+			}
+
+			preds[i] = pred
+			predRefs[i] = predRef
+			succs[i] = curr
+		}
+
+		return curr != nil && list.compare(score, key, curr) == 0
+	}
+}
+
+// casNext replaces old with a ref to node if old is not marked.
+func casNext(next *atomic.Pointer[lockFreeRef], old *lockFreeRef, node *lockFreeNode) bool {
+	if old.marked {
+		return false
+	}
+
+	return next.CompareAndSwap(old, &lockFreeRef{node: node})
+}
+
+func (list *LockFreeSkipList) randLevel() int {
+	const prob = 1 << 30 // Half of 2^31.
+	i := 1
+
+	// Global functions in math/rand are safe for concurrent use.
+	for ; i < list.maxLevel; i++ {
+		if rand.Int31() < prob {
+			break
+		}
+	}
+
+	return i
+}
+
+// compare compares key with node's key by score first and returns -1, 0 and 1.
+func (list *LockFreeSkipList) compare(score float64, key interface{}, rhs *lockFreeNode) int {
+	if score != rhs.score {
+		if score > rhs.score {
+			return 1
+		}
+
+		return -1
+	}
+
+	return list.comparable.Compare(key, rhs.key)
+}
@@ -0,0 +1,164 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestLockFreeSkipList(t *testing.T) {
+	a := assert.New(t)
+	list := NewLockFree(Int)
+
+	_, ok := list.Get(1)
+	a.Assert(!ok)
+	_, ok = list.Remove(1)
+	a.Assert(!ok)
+
+	a.Assert(list.Set(30, "thirty"))
+	a.Assert(list.Set(10, "ten"))
+	a.Assert(list.Set(20, "twenty"))
+	a.Assert(!list.Set(20, "TWENTY"))
+	a.Equal(list.Len(), 3)
+
+	v, ok := list.Get(20)
+	a.Assert(ok)
+	a.Equal(v, "TWENTY")
+
+	keys := []interface{}{}
+	values := []interface{}{}
+
+	for k, v := range list.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	a.Equal(keys, []interface{}{10, 20, 30})
+	a.Equal(values, []interface{}{"ten", "TWENTY", "thirty"})
+
+	v, ok = list.Remove(10)
+	a.Assert(ok)
+	a.Equal(v, "ten")
+	_, ok = list.Get(10)
+	a.Assert(!ok)
+	_, ok = list.Remove(10)
+	a.Assert(!ok)
+	a.Equal(list.Len(), 2)
+}
+
+func TestLockFreeSkipListSetRemoving(t *testing.T) {
+	a := assert.New(t)
+	list := NewLockFree(Int)
+	list.Set(10, "ten")
+
+	// Pretend that Remove has claimed the value but hasn't marked level 0 yet.
+	node := list.head.next[0].Load().node
+	old := node.value.Swap(lockFreeTombstone)
+	a.Equal(*old, "ten")
+	list.length.Add(-1)
+
+	_, ok := list.Get(10)
+	a.Assert(!ok)
+	_, ok = list.Remove(10)
+	a.Assert(!ok)
+
+	// Set must not update the removed node.
+	a.Assert(list.Set(10, "TEN"))
+	a.Assert(node.next[0].Load().marked)
+	a.Equal(list.Len(), 1)
+
+	v, ok := list.Get(10)
+	a.Assert(ok)
+	a.Equal(v, "TEN")
+}
+
+func TestLockFreeSkipListStress(t *testing.T) {
+	a := assert.New(t)
+	list := NewLockFree(Int)
+
+	const workers = 16
+	const N = 5000
+	const keys = 512
+	var wg sync.WaitGroup
+	inserted := make([]int, workers)
+	removed := make([]int, workers)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+
+			for i := 0; i < N; i++ {
+				key := rnd.Intn(keys)
+
+				switch rnd.Intn(4) {
+				case 0, 1:
+					if list.Set(key, key*2) {
+						inserted[w]++
+					}
+
+				case 2:
+					if _, ok := list.Remove(key); ok {
+						removed[w]++
+					}
+
+				case 3:
+					if v, ok := list.Get(key); ok && v != key*2 {
+						t.Errorf("unexpected value %v for key %v", v, key)
+					}
+
+					prev := -1
+
+					for k := range list.All() {
+						if k.(int) <= prev {
+							t.Errorf("keys are not sorted: %v <= %v", k, prev)
+							break
+						}
+
+						prev = k.(int)
+					}
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	total := 0
+
+	for w := 0; w < workers; w++ {
+		total += inserted[w] - removed[w]
+	}
+
+	cnt := 0
+
+	for k, v := range list.All() {
+		a.Equal(v, k.(int)*2)
+		cnt++
+	}
+
+	a.Equal(cnt, total)
+	a.Equal(list.Len(), total)
+
+	// All levels must be sorted and contain no removed node after quiescence.
+	for i := 0; i < list.maxLevel; i++ {
+		var prev *lockFreeNode
+
+		for curr := list.head.next[i].Load().node; curr != nil; curr = curr.next[i].Load().node {
+			a.Assert(!curr.next[i].Load().marked)
+
+			if prev != nil {
+				a.Assert(list.comparable.Compare(prev.key, curr.key) < 0)
+			}
+
+			prev = curr
+		}
+	}
+}
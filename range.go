@@ -0,0 +1,145 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+// RangeOptions controls the bounds and the size of a range returned by Range.
+// The zero value means both ends are inclusive and there is no limit.
+type RangeOptions struct {
+	ExcludeFrom bool // Excludes the element equal to from.
+	ExcludeTo   bool // Excludes the element equal to to.
+
+	Offset int // Skips first Offset elements in the range.
+	Limit  int // Returns at most Limit elements. If Limit is not positive, there is no limit.
+}
+
+// RangeIterator iterates elements in a range of keys.
+// It's created by Range.
+//
+// The iterator holds the element to be returned by next Next call.
+// If the list is changed during iteration, the iterator sees the change
+// as long as that element is still in the list.
+// If that element is removed, the iteration stops.
+// If that element is rekeyed, the iteration continues with
+// the first element whose key is not less than its old key.
+type RangeIterator struct {
+	list      *SkipList
+	next      *Element
+	nextKey   interface{} // The key of next when it's held.
+	nextScore float64
+
+	to        interface{}
+	toScore   float64
+	hasTo     bool
+	excludeTo bool
+
+	remaining int // Negative means unlimited.
+}
+
+// Range returns an iterator for all elements with keys between from and to.
+// If from is nil, the range starts from the front of the list.
+// If to is nil, the range ends at the back of the list.
+// If opts is nil, both ends are inclusive and there is no limit.
+//
+// Here is a sample to iterate a range.
+//
+//     it := list.Range(10, 20, &skiplist.RangeOptions{ExcludeTo: true})
+//
+//     for elem := it.Next(); elem != nil; elem = it.Next() {
+//         // Use elem.
+//     }
+//
+// The complexity is O(log(N)) to seek the first element.
+func (list *SkipList) Range(from, to interface{}, opts *RangeOptions) *RangeIterator {
+	if opts == nil {
+		opts = &RangeOptions{}
+	}
+
+	it := &RangeIterator{
+		list:      list,
+		to:        to,
+		hasTo:     to != nil,
+		excludeTo: opts.ExcludeTo,
+		remaining: -1,
+	}
+
+	if opts.Limit > 0 {
+		it.remaining = opts.Limit
+	}
+
+	if it.hasTo {
+		it.toScore = list.calcScore(to)
+	}
+
+	var start *Element
+
+	if from == nil {
+		start = list.Front()
+	} else {
+		score := list.calcScore(from)
+
+		if opts.ExcludeFrom {
+			start = list.higher(score, from)
+		} else {
+			start = list.findNext(nil, score, from)
+		}
+	}
+
+	// Use spans to skip elements in O(log(N)).
+	if start != nil && opts.Offset > 0 {
+		start = list.GetByIndex(start.Index() + opts.Offset)
+	}
+
+	it.hold(start)
+	return it
+}
+
+// hold holds elem as the element to be returned by next Next call.
+func (it *RangeIterator) hold(elem *Element) {
+	it.next = elem
+
+	if elem != nil {
+		it.nextKey = elem.key
+		it.nextScore = elem.score
+	}
+}
+
+// Next returns next element in the range.
+// If there is no more element, returns nil.
+func (it *RangeIterator) Next() (elem *Element) {
+	if it.next == nil || it.remaining == 0 {
+		return
+	}
+
+	if it.next.list != it.list {
+		it.next = nil
+		return
+	}
+
+	// The element is rekeyed. Seek the first element not less than its old key.
+	if it.list.compare(it.nextScore, it.nextKey, it.next) != 0 {
+		it.hold(it.list.findNext(nil, it.nextScore, it.nextKey))
+
+		if it.next == nil {
+			return
+		}
+	}
+
+	if it.hasTo {
+		comp := it.list.compare(it.toScore, it.to, it.next)
+
+		if comp < 0 || comp == 0 && it.excludeTo {
+			it.next = nil
+			return
+		}
+	}
+
+	elem = it.next
+	it.hold(elem.Next())
+
+	if it.remaining > 0 {
+		it.remaining--
+	}
+
+	return
+}
@@ -0,0 +1,92 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func collectRange(it *RangeIterator) (keys []int) {
+	keys = []int{}
+
+	for elem := it.Next(); elem != nil; elem = it.Next() {
+		keys = append(keys, elem.Key().(int))
+	}
+
+	return
+}
+
+func TestRange(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	a.Equal(collectRange(list.Range(nil, nil, nil)), []int{})
+
+	for i := 0; i < 10; i++ {
+		list.Set(i*10, i)
+	}
+
+	cases := []struct {
+		from, to interface{}
+		opts     *RangeOptions
+		keys     []int
+	}{
+		{nil, nil, nil, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}},
+		{20, 50, nil, []int{20, 30, 40, 50}},
+		{15, 55, nil, []int{20, 30, 40, 50}},
+		{20, 50, &RangeOptions{ExcludeFrom: true}, []int{30, 40, 50}},
+		{20, 50, &RangeOptions{ExcludeTo: true}, []int{20, 30, 40}},
+		{20, 50, &RangeOptions{ExcludeFrom: true, ExcludeTo: true}, []int{30, 40}},
+		{15, 55, &RangeOptions{ExcludeFrom: true, ExcludeTo: true}, []int{20, 30, 40, 50}},
+		{nil, 25, nil, []int{0, 10, 20}},
+		{75, nil, nil, []int{80, 90}},
+		{20, nil, &RangeOptions{Offset: 2}, []int{40, 50, 60, 70, 80, 90}},
+		{20, nil, &RangeOptions{Limit: 3}, []int{20, 30, 40}},
+		{20, 70, &RangeOptions{Offset: 1, Limit: 2}, []int{30, 40}},
+		{20, 40, &RangeOptions{Offset: 1, Limit: 5}, []int{30, 40}},
+		{20, 40, &RangeOptions{Offset: 5}, []int{}},
+		{80, nil, &RangeOptions{Offset: 5}, []int{}},
+		{50, 20, nil, []int{}},
+		{50, 50, nil, []int{50}},
+		{50, 50, &RangeOptions{ExcludeTo: true}, []int{}},
+		{100, nil, nil, []int{}},
+		{nil, -1, nil, []int{}},
+	}
+
+	for i, c := range cases {
+		a.Use(&i, &c)
+		a.Equal(collectRange(list.Range(c.from, c.to, c.opts)), c.keys)
+	}
+}
+
+func TestRangeWithModification(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	for i := 0; i < 10; i++ {
+		list.Set(i, i)
+	}
+
+	it := list.Range(2, 8, nil)
+	a.Equal(it.Next().Key(), 2)
+
+	// Changes after current position are visible.
+	list.Set(3, "updated")
+	list.Remove(4)
+	elem := it.Next()
+	a.Equal(elem.Key(), 3)
+	a.Equal(elem.Value, "updated")
+	a.Equal(it.Next().Key(), 5)
+
+	// Iteration continues from the old key if next element is rekeyed.
+	a.Assert(list.Rekey(list.Get(6), 100))
+	a.Equal(it.Next().Key(), 7)
+
+	// Iteration stops if next element is removed.
+	list.Remove(8)
+	a.Equal(it.Next(), nil)
+	a.Equal(it.Next(), nil)
+}
@@ -0,0 +1,115 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math"
+)
+
+// LevelStats is the statistics of element levels in a list.
+type LevelStats struct {
+	MaxLevel  int   // The highest level of all elements.
+	Pointers  int   // Total count of levels of all elements, which is the count of next pointers.
+	Histogram []int // Histogram[i] is the count of elements with level i+1.
+}
+
+// Rebalance reassigns levels of all elements to the ideal distribution
+// for current length, probability and max level.
+// Let b be round(1/p), where p is the probability set in Options.Probability.
+// The element at 1-based position r gets 1+k levels, where k is how many times r is divisible by b,
+// so that every level has 1/b of elements of the level below it.
+// Levels are capped by MaxLevel(), or by the max level estimated for current length
+// if the list is created with Options.AutoMaxLevel.
+//
+// Elements are relinked in place. All element pointers are still valid.
+// Levels of the list header are trimmed to MaxLevel() as well.
+// It's useful after SetMaxLevel shrinks max level or random levels are skewed.
+//
+// Returns level statistics before and after rebalance.
+//
+// The complexity is O(N).
+func (list *SkipList) Rebalance() (before, after LevelStats) {
+	before = list.levelStats()
+	elem := list.Front()
+	base := list.levelBase()
+	max := list.maxLevel
+
+	if list.autoMaxLevel {
+		list.resetAutoLevel()
+		max = list.estimateLevel()
+	}
+
+	list.levels = make([]*Element, list.maxLevel)
+	list.spans = make([]int, list.maxLevel)
+	list.resetAggregates(&list.elementHeader)
+	list.back = nil
+	list.level = 0
+	list.length = 0
+	app := newAppender(list)
+
+	for rank := 1; elem != nil; rank++ {
+		next := elem.Next()
+		elem.resize(idealLevel(rank, base, max))
+		app.appendElement(elem)
+		elem = next
+	}
+
+	after = list.levelStats()
+	return
+}
+
+// levelBase returns round(1/p). Every level has 1/base of elements of the level below it
+// in a perfectly balanced list.
+func (list *SkipList) levelBase() int {
+	base := int(math.Round(1 / list.probability))
+
+	if base < 2 {
+		base = 2
+	}
+
+	return base
+}
+
+// idealLevel returns the level of the element at 1-based rank in a perfectly balanced list
+// with base and max level.
+func idealLevel(rank, base, max int) int {
+	level := 1
+
+	for ; level < max && rank%base == 0; level++ {
+		rank /= base
+	}
+
+	return level
+}
+
+func (list *SkipList) levelStats() (stats LevelStats) {
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		level := elem.Level()
+
+		for len(stats.Histogram) < level {
+			stats.Histogram = append(stats.Histogram, 0)
+		}
+
+		stats.Histogram[level-1]++
+		stats.Pointers += level
+	}
+
+	stats.MaxLevel = len(stats.Histogram)
+	return
+}
+
+// resize changes levels of elem to level and clears all levels.
+// Memory is reallocated if the capacity doesn't match level to release unused levels.
+func (elem *Element) resize(level int) {
+	if cap(elem.levels) != level {
+		elem.levels = make([]*Element, level)
+		elem.spans = make([]int, level)
+		return
+	}
+
+	elem.levels = elem.levels[:level]
+	elem.spans = elem.spans[:level]
+	clear(elem.levels)
+	clear(elem.spans)
+}
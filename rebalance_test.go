@@ -0,0 +1,100 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestRebalance(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	before, after := list.Rebalance()
+	a.Equal(before, LevelStats{})
+	a.Equal(after, LevelStats{})
+
+	const N = 1023
+	elems := make([]*Element, 0, N)
+
+	for i := 0; i < N; i++ {
+		elems = append(elems, list.Set(i, i))
+	}
+
+	pointers := 0
+
+	for _, elem := range elems {
+		pointers += elem.Level()
+	}
+
+	// Elements created before SetMaxLevel keep their levels.
+	list.SetMaxLevel(4)
+	before, after = list.Rebalance()
+	assertSanity(a, list)
+	a.Equal(before.Pointers, pointers)
+	a.Equal(len(before.Histogram), before.MaxLevel)
+	a.Equal(after.MaxLevel, 4)
+	a.Equal(after.Histogram, []int{512, 256, 128, 127})
+	a.Equal(after.Pointers, 512+256*2+128*3+127*4)
+	a.Equal(len(list.levels), 4)
+
+	for i, elem := range elems {
+		a.Equal(elem.Index(), i)
+		a.Equal(list.Get(i), elem)
+	}
+
+	list.SetMaxLevel(DefaultMaxLevel)
+	_, after = list.Rebalance()
+	assertSanity(a, list)
+	a.Equal(after.MaxLevel, 10)
+	a.Equal(after.Histogram, []int{512, 256, 128, 64, 32, 16, 8, 4, 2, 1})
+
+	// List works as usual after rebalance.
+	for i := 0; i < N; i += 2 {
+		list.Remove(i)
+	}
+
+	for i := N; i < N*2; i++ {
+		list.Set(i, i)
+	}
+
+	assertSanity(a, list)
+	a.Equal(list.Len(), N/2+N)
+}
+
+func TestRebalanceProbability(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{
+		Probability:  0.25,
+		AutoMaxLevel: true,
+	})
+
+	const N = 4096
+
+	for i := 0; i < N; i++ {
+		list.Set(i, i)
+	}
+
+	_, after := list.Rebalance()
+	assertSanity(a, list)
+	a.Equal(after.Histogram, []int{3072, 768, 192, 48, 12, 3, 1})
+	a.Equal(after.Pointers, 3072+768*2+192*3+48*4+12*5+3*6+1*7)
+
+	// Levels shrink with the list.
+	for i := 0; i < N-10; i++ {
+		list.Remove(i)
+	}
+
+	_, after = list.Rebalance()
+	assertSanity(a, list)
+	a.Equal(after.Histogram, []int{8, 2})
+
+	// The max level is capped by MaxLevel().
+	list.SetMaxLevel(1)
+	_, after = list.Rebalance()
+	assertSanity(a, list)
+	a.Equal(after.Histogram, []int{10})
+}
@@ -0,0 +1,66 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+// Rekey changes the key of elem to key and moves elem to keep the list sorted.
+// The elem is moved in place, so the elem pointer is still valid and no element is allocated.
+// If elem is still between its previous and next elements with the new key, it's not moved at all.
+//
+// Returns false and does nothing if elem is not in the list,
+// or duplicates are not allowed and there is another element with the key.
+// If duplicates are allowed and elem is moved, it's placed after all elements with the key.
+//
+// The complexity is O(log(N)). It's O(1) if elem is not moved and the list is not created with a monoid.
+func (list *SkipList) Rekey(elem *Element, key interface{}) (ok bool) {
+	if elem == nil || elem.list != list {
+		return
+	}
+
+	score := list.calcScore(key)
+
+	if list.fits(elem, score, key) {
+		elem.key = key
+		elem.score = score
+		list.UpdateAggregates(elem)
+		ok = true
+		return
+	}
+
+	if !list.duplicates {
+		if next := list.findNext(nil, score, key); next != nil && list.compare(score, key, next) == 0 {
+			return
+		}
+	}
+
+	list.unlink(elem)
+	elem.key = key
+	elem.score = score
+
+	var path searchPath
+	prevElemHeaders, prevRanks := path.alloc(len(list.levels))
+	list.search(score, key, true, prevElemHeaders, prevRanks)
+	list.linkElement(elem, prevElemHeaders, prevRanks)
+	ok = true
+	return
+}
+
+// fits returns true if the key is still between elem's previous and next elements.
+func (list *SkipList) fits(elem *Element, score float64, key interface{}) bool {
+	// The comp must be greater than or equal to limit to be after prev.
+	limit := 1
+
+	if list.duplicates {
+		limit = 0
+	}
+
+	if prev := elem.prev; prev != nil && list.compare(score, key, prev) < limit {
+		return false
+	}
+
+	if next := elem.Next(); next != nil && list.compare(score, key, next) > -limit {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,121 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestRekey(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	for i := 0; i < 10; i++ {
+		list.Set(i*10, i)
+	}
+
+	// Not moved.
+	elem := list.Get(50)
+	prev := elem.Prev()
+	a.Assert(list.Rekey(elem, 55))
+	a.Equal(elem.Key(), 55)
+	a.Equal(elem.Prev(), prev)
+	a.Equal(list.Get(55), elem)
+	assertSanity(a, list)
+
+	// Moved forward and backward.
+	a.Assert(list.Rekey(elem, 1000))
+	a.Equal(list.Back(), elem)
+	a.Equal(elem.Index(), 9)
+	a.Assert(list.Get(55) == nil)
+	assertSanity(a, list)
+
+	a.Assert(list.Rekey(elem, -1))
+	a.Equal(list.Front(), elem)
+	a.Equal(elem.Index(), 0)
+	a.Equal(elem.Value, 5)
+	assertSanity(a, list)
+
+	// Key exists.
+	a.Assert(!list.Rekey(elem, 40))
+	a.Equal(elem.Key(), -1)
+	a.Assert(list.Rekey(elem, -1))
+	assertSanity(a, list)
+
+	// Elem not in list.
+	removed := list.Remove(40)
+	a.Assert(!list.Rekey(removed, 45))
+	a.Assert(!list.Rekey(nil, 45))
+	a.Assert(!New(Int).Rekey(elem, 45))
+	a.Equal(list.Len(), 9)
+
+	// Single element list.
+	single := New(Int)
+	elem = single.Set(1, "one")
+	a.Assert(single.Rekey(elem, 2))
+	a.Equal(single.Front(), elem)
+	assertSanity(a, single)
+}
+
+func TestRekeyRandom(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+	elems := map[int]*Element{}
+
+	const N = 1000
+
+	for i := 0; i < N; i++ {
+		elems[i] = list.Set(i, i)
+	}
+
+	for i := 0; i < N*10; i++ {
+		old := rand.Intn(N * 2)
+		elem, ok := elems[old]
+
+		if !ok {
+			continue
+		}
+
+		key := rand.Intn(N * 2)
+		_, exists := elems[key]
+
+		if exists && key != old {
+			a.Assert(!list.Rekey(elem, key))
+			continue
+		}
+
+		a.Assert(list.Rekey(elem, key))
+		delete(elems, old)
+		elems[key] = elem
+	}
+
+	assertSanity(a, list)
+	a.Equal(list.Len(), N)
+
+	for key, elem := range elems {
+		a.Equal(list.Get(key), elem)
+	}
+}
+
+func TestRekeyDuplicates(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{AllowDuplicates: true})
+	list.Add(1, "a")
+	list.Add(2, "b")
+	list.Add(2, "c")
+	elem := list.Add(3, "d")
+
+	a.Assert(list.Rekey(elem, 2))
+	a.Equal(list.Count(2), 3)
+	a.Equal(list.Back(), elem)
+
+	elem = list.Front()
+	a.Assert(list.Rekey(elem, 2))
+	a.Equal(list.Front(), elem)
+	a.Equal(list.Count(2), 4)
+	assertSanity(a, list)
+}
@@ -0,0 +1,352 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Codec encodes and decodes keys or values when serializing a skip list.
+//
+// All built-in key types like Int, String, etc. implement Codec.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// GobCodec encodes and decodes values with encoding/gob.
+// Any concrete type other than built-in types must be registered by gob.Register.
+//
+// It's the default value codec of a skip list.
+type GobCodec struct{}
+
+var _ Codec = GobCodec{}
+
+// Marshal encodes v with gob.
+func (GobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes data encoded by Marshal.
+func (GobCodec) Unmarshal(data []byte) (v interface{}, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return
+}
+
+// ErrCorruptedData is returned when decoding a serialized skip list with invalid data.
+var ErrCorruptedData = errors.New("skiplist: corrupted data")
+
+var (
+	_ encoding.BinaryMarshaler   = new(SkipList)
+	_ encoding.BinaryUnmarshaler = new(SkipList)
+	_ io.WriterTo                = new(SkipList)
+	_ io.ReaderFrom              = new(SkipList)
+)
+
+// Serialized data layout.
+//
+//     magic   [4]byte // "SKPL"
+//     version byte    // serializeVersion
+//     count   uvarint // Number of elements.
+//     elements        // Sorted from front to back. Each element is:
+//         keyLen   uvarint
+//         key      [keyLen]byte
+//         valueLen uvarint
+//         value    [valueLen]byte
+const (
+	serializeMagic   = "SKPL"
+	serializeVersion = 1
+
+	maxPreallocSize = 1 << 16
+)
+
+// SetCodec sets codecs to encode and decode keys and values in serialization.
+//
+// If keyCodec is nil, the comparable passed to New is used as key codec
+// when it implements Codec. All built-in key types implement Codec.
+// If valueCodec is nil, GobCodec is used.
+func (list *SkipList) SetCodec(keyCodec, valueCodec Codec) {
+	list.keyCodec = keyCodec
+	list.valueCodec = valueCodec
+}
+
+func (list *SkipList) codecs() (keyCodec, valueCodec Codec, err error) {
+	keyCodec = list.keyCodec
+	valueCodec = list.valueCodec
+
+	if keyCodec == nil {
+		if c, ok := list.comparable.(Codec); ok {
+			keyCodec = c
+		} else {
+			err = errors.New("skiplist: key codec must be set by SetCodec for custom comparable")
+			return
+		}
+	}
+
+	if valueCodec == nil {
+		valueCodec = GobCodec{}
+	}
+
+	return
+}
+
+// MarshalBinary encodes all keys and values in the list.
+// Keys and values are encoded by codecs set by SetCodec.
+func (list *SkipList) MarshalBinary() (data []byte, err error) {
+	var buf bytes.Buffer
+
+	if _, err = list.WriteTo(&buf); err != nil {
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+// UnmarshalBinary discards all existing elements and decodes elements from data.
+// The data must be encoded by MarshalBinary or WriteTo with the same comparable and codecs.
+//
+// The complexity is O(N).
+func (list *SkipList) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	if _, err := list.ReadFrom(r); err != nil {
+		return err
+	}
+
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %v trailing bytes", ErrCorruptedData, r.Len())
+	}
+
+	return nil
+}
+
+// WriteTo writes all keys and values in the list to w.
+// Keys and values are encoded by codecs set by SetCodec.
+//
+// The complexity is O(N).
+func (list *SkipList) WriteTo(w io.Writer) (n int64, err error) {
+	keyCodec, valueCodec, err := list.codecs()
+
+	if err != nil {
+		return
+	}
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	var buf [binary.MaxVarintLen64]byte
+
+	writeBytes := func(data []byte) {
+		if err != nil {
+			return
+		}
+
+		if _, err = bw.Write(binary.AppendUvarint(buf[:0], uint64(len(data)))); err != nil {
+			return
+		}
+
+		_, err = bw.Write(data)
+	}
+
+	bw.WriteString(serializeMagic)
+	bw.WriteByte(serializeVersion)
+	_, err = bw.Write(binary.AppendUvarint(buf[:0], uint64(list.length)))
+
+	for elem := list.Front(); elem != nil && err == nil; elem = elem.Next() {
+		var key, value []byte
+
+		if key, err = keyCodec.Marshal(elem.key); err != nil {
+			break
+		}
+
+		if value, err = valueCodec.Marshal(elem.Value); err != nil {
+			break
+		}
+
+		writeBytes(key)
+		writeBytes(value)
+	}
+
+	if err == nil {
+		err = bw.Flush()
+	}
+
+	n = cw.n
+	return
+}
+
+// ReadFrom discards all existing elements and reads elements from r.
+// The data must be written by WriteTo or MarshalBinary with the same comparable and codecs.
+// As elements are sorted in data, the list is rebuilt in O(N).
+//
+// If r doesn't implement io.ByteReader, it's wrapped by a bufio.Reader
+// which may read more bytes than needed from r.
+//
+// If any error occurs, the list is empty.
+func (list *SkipList) ReadFrom(r io.Reader) (n int64, err error) {
+	keyCodec, valueCodec, err := list.codecs()
+
+	if err != nil {
+		return
+	}
+
+	br, ok := r.(io.ByteReader)
+
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	cr := &countingReader{r: br}
+	list.Init()
+
+	defer func() {
+		n = cr.n
+
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			list.Init()
+		}
+	}()
+
+	var header [len(serializeMagic) + 1]byte
+
+	if _, err = io.ReadFull(cr, header[:]); err != nil {
+		return
+	}
+
+	if string(header[:len(serializeMagic)]) != serializeMagic {
+		err = fmt.Errorf("%w: invalid magic", ErrCorruptedData)
+		return
+	}
+
+	if version := header[len(serializeMagic)]; version != serializeVersion {
+		err = fmt.Errorf("%w: unsupported version %v", ErrCorruptedData, version)
+		return
+	}
+
+	count, err := binary.ReadUvarint(cr)
+
+	if err != nil {
+		return
+	}
+
+	readBytes := func() (data []byte, err error) {
+		size, err := binary.ReadUvarint(cr)
+
+		if err != nil {
+			return
+		}
+
+		if size > maxPreallocSize {
+			// Avoid allocating huge memory for corrupted size.
+			var buf bytes.Buffer
+
+			if size > math.MaxInt64 {
+				err = fmt.Errorf("%w: invalid size %v", ErrCorruptedData, size)
+				return
+			}
+
+			_, err = io.CopyN(&buf, cr, int64(size))
+			data = buf.Bytes()
+			return
+		}
+
+		data = make([]byte, size)
+		_, err = io.ReadFull(cr, data)
+		return
+	}
+
+	app := newAppender(list)
+
+	for i := uint64(0); i < count; i++ {
+		var data []byte
+		var key, value interface{}
+
+		if data, err = readBytes(); err != nil {
+			return
+		}
+
+		if key, err = keyCodec.Unmarshal(data); err != nil {
+			return
+		}
+
+		if data, err = readBytes(); err != nil {
+			return
+		}
+
+		if value, err = valueCodec.Unmarshal(data); err != nil {
+			return
+		}
+
+		score := list.calcScore(key)
+
+		if list.back != nil && !list.appendable(score, key) {
+			err = fmt.Errorf("%w: key `%v` is out of order after previous key `%v`", ErrCorruptedData, key, list.back.key)
+			return
+		}
+
+		app.Append(score, key, value)
+	}
+
+	return
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.n += int64(n)
+	return
+}
+
+type countingReader struct {
+	r io.ByteReader
+	n int64
+}
+
+func (cr *countingReader) ReadByte() (b byte, err error) {
+	b, err = cr.r.ReadByte()
+
+	if err == nil {
+		cr.n++
+	}
+
+	return
+}
+
+func (cr *countingReader) Read(p []byte) (n int, err error) {
+	if r, ok := cr.r.(io.Reader); ok {
+		n, err = r.Read(p)
+		cr.n += int64(n)
+		return
+	}
+
+	for ; n < len(p); n++ {
+		if p[n], err = cr.ReadByte(); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,230 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	a := assert.New(t)
+	list := New(IntDesc)
+	rnd := rand.New(rand.NewSource(0x6a09e667))
+
+	for i := 0; i < 1000; i++ {
+		list.Set(rnd.Intn(5000)-2500, fmt.Sprint("value-", i))
+	}
+
+	data, err := list.MarshalBinary()
+	a.NilError(err)
+
+	loaded := New(IntDesc)
+	loaded.Set(99999, "discarded")
+	a.NilError(loaded.UnmarshalBinary(data))
+	a.Equal(loaded.Len(), list.Len())
+
+	for elem, loadedElem := list.Front(), loaded.Front(); elem != nil; elem, loadedElem = elem.Next(), loadedElem.Next() {
+		a.Equal(loadedElem.Key(), elem.Key())
+		a.Equal(loadedElem.Value, elem.Value)
+		a.Equal(loadedElem.Score(), elem.Score())
+	}
+
+	assertSanity(a, loaded)
+
+	// Loaded list works as usual.
+	loaded.Set(-99999, "new")
+	a.Equal(loaded.Back().Value, "new")
+	a.Equal(loaded.Get(99999), nil)
+	assertSanity(a, loaded)
+
+	// Empty list.
+	data, err = New(String).MarshalBinary()
+	a.NilError(err)
+	loaded = New(String)
+	a.NilError(loaded.UnmarshalBinary(data))
+	a.Equal(loaded.Len(), 0)
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	a := assert.New(t)
+	list := New(String)
+	list.Set("foo", 1)
+	list.Set("bar", 2.5)
+	list.Set("baz", []byte("bytes"))
+
+	var buf bytes.Buffer
+	written, err := list.WriteTo(&buf)
+	a.NilError(err)
+	a.Equal(written, int64(buf.Len()))
+
+	// Write some more data after the list.
+	buf.WriteString("tail")
+
+	loaded := New(String)
+	read, err := loaded.ReadFrom(&buf)
+	a.NilError(err)
+	a.Equal(read, written)
+	a.Equal(buf.String(), "tail")
+	a.Equal(loaded.Len(), 3)
+	a.Equal(loaded.MustGetValue("foo"), 1)
+	a.Equal(loaded.MustGetValue("bar"), 2.5)
+	a.Equal(loaded.MustGetValue("baz"), []byte("bytes"))
+	assertSanity(a, loaded)
+}
+
+type testStringCodec struct{}
+
+func (testStringCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(v.(string)), nil
+}
+
+func (testStringCodec) Unmarshal(data []byte) (interface{}, error) {
+	return string(data), nil
+}
+
+type testIntStringCodec struct{}
+
+func (testIntStringCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(strconv.Itoa(v.(int))), nil
+}
+
+func (testIntStringCodec) Unmarshal(data []byte) (interface{}, error) {
+	return strconv.Atoi(string(data))
+}
+
+func TestCustomCodec(t *testing.T) {
+	a := assert.New(t)
+	comparable := GreaterThanFunc(func(lhs, rhs interface{}) int {
+		return Int.Compare(lhs, rhs)
+	})
+	list := New(comparable)
+	list.Set(3, "three")
+	list.Set(1, "one")
+
+	_, err := list.MarshalBinary()
+	a.NonNilError(err)
+
+	list.SetCodec(testIntStringCodec{}, testStringCodec{})
+	data, err := list.MarshalBinary()
+	a.NilError(err)
+
+	loaded := New(comparable)
+	loaded.SetCodec(testIntStringCodec{}, testStringCodec{})
+	a.NilError(loaded.UnmarshalBinary(data))
+	a.Equal(loaded.Len(), 2)
+	a.Equal(loaded.Front().Value, "one")
+	a.Equal(loaded.Back().Value, "three")
+	assertSanity(a, loaded)
+}
+
+func TestUnmarshalCorruptedData(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+	list.Set(1, "a")
+	list.Set(2, "b")
+	data, err := list.MarshalBinary()
+	a.NilError(err)
+
+	loaded := New(Int)
+
+	for i := 0; i < len(data); i++ {
+		err := loaded.UnmarshalBinary(data[:i])
+		a.Use(&i)
+		a.Assert(err != nil)
+		a.Equal(loaded.Len(), 0)
+	}
+
+	err = loaded.UnmarshalBinary(append(append([]byte{}, data...), 0))
+	a.Assert(errors.Is(err, ErrCorruptedData))
+
+	corrupted := append([]byte{}, data...)
+	corrupted[0] = 'X'
+	a.Assert(errors.Is(loaded.UnmarshalBinary(corrupted), ErrCorruptedData))
+
+	corrupted = append([]byte{}, data...)
+	corrupted[len(serializeMagic)] = serializeVersion + 1
+	a.Assert(errors.Is(loaded.UnmarshalBinary(corrupted), ErrCorruptedData))
+
+	// Keys are out of order.
+	desc := New(IntDesc)
+	desc.Set(1, "a")
+	desc.Set(2, "b")
+	data, err = desc.MarshalBinary()
+	a.NilError(err)
+	a.Assert(errors.Is(loaded.UnmarshalBinary(data), ErrCorruptedData))
+	a.Equal(loaded.Len(), 0)
+
+	_, err = loaded.ReadFrom(bytes.NewReader(nil))
+	a.Equal(err, io.ErrUnexpectedEOF)
+}
+
+func TestKeyTypeCodec(t *testing.T) {
+	a := assert.New(t)
+	cases := []struct {
+		kt  keyType
+		key interface{}
+	}{
+		{Int, -12345},
+		{Int8, int8(-8)},
+		{Int16, int16(16)},
+		{Int32, int32(-32)},
+		{Int64, int64(-1 << 62)},
+		{Uint, uint(12345)},
+		{Uint8, uint8(255)},
+		{Uint16, uint16(16)},
+		{Uint32, uint32(32)},
+		{Uint64, uint64(1<<64 - 1)},
+		{Uintptr, uintptr(64)},
+		{Float32, float32(1.5)},
+		{Float64Desc, -2.25},
+		{String, "hello"},
+		{Bytes, []byte("world")},
+		{Rune, 'r'},
+		{Byte, byte('b')},
+	}
+
+	for i, c := range cases {
+		a.Use(&i, &c)
+
+		data, err := c.kt.Marshal(c.key)
+		a.NilError(err)
+		key, err := c.kt.Unmarshal(data)
+		a.NilError(err)
+		a.Equal(key, c.key)
+	}
+
+	// Constant values are converted to the key type.
+	data, err := Float64.Marshal(12)
+	a.NilError(err)
+	key, err := Float64.Unmarshal(data)
+	a.NilError(err)
+	a.Equal(key, 12.0)
+
+	_, err = Int.Marshal("not an int")
+	a.NonNilError(err)
+	_, err = Int8.Unmarshal(Int.mustMarshal(1000))
+	a.NonNilError(err)
+	_, err = Float64.Unmarshal([]byte{1, 2, 3})
+	a.NonNilError(err)
+	_, err = Int.Unmarshal(nil)
+	a.NonNilError(err)
+}
+
+func (kt keyType) mustMarshal(key interface{}) []byte {
+	data, err := kt.Marshal(key)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
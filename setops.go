@@ -0,0 +1,274 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"reflect"
+)
+
+// MergeFunc decides the value of key in the result of Union or Intersect
+// when both lists hold the key.
+// The lhs is the value in the first list and rhs is the value in the second list.
+type MergeFunc func(key, lhs, rhs interface{}) interface{}
+
+// Errors returned by set operations.
+var (
+	// ErrDifferentComparable is returned when lists are not created with the same comparable.
+	// Comparables are the same if they are equal by == or they are funcs pointing to the same func.
+	// A comparable of any other non-comparable type, e.g. a struct holding a slice,
+	// or a struct holding a func in an interface field,
+	// is never the same as another one. Use a pointer to such a comparable instead.
+	ErrDifferentComparable  = errors.New("skiplist: lists must be created with the same comparable")
+	ErrDuplicatesNotAllowed = errors.New("skiplist: lists allowing duplicates are not supported")
+)
+
+// Union returns a new list with all keys in a or b.
+// If both lists hold a key, the value is decided by merge.
+// If merge is nil, the value in a is used.
+//
+// The result has the same options and codecs as a.
+// Both lists must be created with the same comparable and must not allow duplicates.
+// Otherwise, returns an error.
+//
+// The complexity is O(N+M).
+func Union(a, b *SkipList, merge MergeFunc) (result *SkipList, err error) {
+	if err = checkSetOperands(a, b); err != nil {
+		return
+	}
+
+	result = a.cloneEmpty()
+	app := newAppender(result)
+	x, y := a.Front(), b.Front()
+
+	for x != nil && y != nil {
+		comp := a.compare(x.score, x.key, y)
+
+		switch {
+		case comp < 0:
+			app.Append(x.score, x.key, x.Value)
+			x = x.Next()
+
+		case comp > 0:
+			app.Append(y.score, y.key, y.Value)
+			y = y.Next()
+
+		default:
+			app.Append(x.score, x.key, mergeValues(merge, x, y))
+			x, y = x.Next(), y.Next()
+		}
+	}
+
+	for ; x != nil; x = x.Next() {
+		app.Append(x.score, x.key, x.Value)
+	}
+
+	for ; y != nil; y = y.Next() {
+		app.Append(y.score, y.key, y.Value)
+	}
+
+	return
+}
+
+// Intersect returns a new list with keys in both a and b.
+// The value is decided by merge.
+// If merge is nil, the value in a is used.
+//
+// The result has the same options and codecs as a.
+// Both lists must be created with the same comparable and must not allow duplicates.
+// Otherwise, returns an error.
+//
+// When one list is much sparser than the other, elements in the denser list
+// are skipped by FindNext instead of walking them one by one.
+// The complexity is O(N+M) in the worst case.
+func Intersect(a, b *SkipList, merge MergeFunc) (result *SkipList, err error) {
+	if err = checkSetOperands(a, b); err != nil {
+		return
+	}
+
+	result = a.cloneEmpty()
+	app := newAppender(result)
+	x, y := a.Front(), b.Front()
+
+	for x != nil && y != nil {
+		comp := a.compare(x.score, x.key, y)
+
+		switch {
+		case comp < 0:
+			x = a.skipTo(x, y)
+
+		case comp > 0:
+			y = b.skipTo(y, x)
+
+		default:
+			app.Append(x.score, x.key, mergeValues(merge, x, y))
+			x, y = x.Next(), y.Next()
+		}
+	}
+
+	return
+}
+
+// Difference returns a new list with keys in a but not in b.
+//
+// The result has the same options and codecs as a.
+// Both lists must be created with the same comparable and must not allow duplicates.
+// Otherwise, returns an error.
+//
+// Elements in b less than the next key in a are skipped by FindNext.
+// The complexity is O(N+M) in the worst case.
+func Difference(a, b *SkipList) (result *SkipList, err error) {
+	if err = checkSetOperands(a, b); err != nil {
+		return
+	}
+
+	result = a.cloneEmpty()
+	app := newAppender(result)
+	x, y := a.Front(), b.Front()
+
+	for x != nil && y != nil {
+		comp := a.compare(x.score, x.key, y)
+
+		switch {
+		case comp < 0:
+			app.Append(x.score, x.key, x.Value)
+			x = x.Next()
+
+		case comp > 0:
+			y = b.skipTo(y, x)
+
+		default:
+			x, y = x.Next(), y.Next()
+		}
+	}
+
+	for ; x != nil; x = x.Next() {
+		app.Append(x.score, x.key, x.Value)
+	}
+
+	return
+}
+
+// SymmetricDifference returns a new list with keys in either a or b but not in both.
+//
+// The result has the same options and codecs as a.
+// Both lists must be created with the same comparable and must not allow duplicates.
+// Otherwise, returns an error.
+//
+// The complexity is O(N+M).
+func SymmetricDifference(a, b *SkipList) (result *SkipList, err error) {
+	if err = checkSetOperands(a, b); err != nil {
+		return
+	}
+
+	result = a.cloneEmpty()
+	app := newAppender(result)
+	x, y := a.Front(), b.Front()
+
+	for x != nil && y != nil {
+		comp := a.compare(x.score, x.key, y)
+
+		switch {
+		case comp < 0:
+			app.Append(x.score, x.key, x.Value)
+			x = x.Next()
+
+		case comp > 0:
+			app.Append(y.score, y.key, y.Value)
+			y = y.Next()
+
+		default:
+			x, y = x.Next(), y.Next()
+		}
+	}
+
+	for ; x != nil; x = x.Next() {
+		app.Append(x.score, x.key, x.Value)
+	}
+
+	for ; y != nil; y = y.Next() {
+		app.Append(y.score, y.key, y.Value)
+	}
+
+	return
+}
+
+// skipTo returns the first element after elem that is greater or equal to target.
+func (list *SkipList) skipTo(elem, target *Element) *Element {
+	next := elem.Next()
+
+	if next == nil {
+		return nil
+	}
+
+	return list.findNext(next, target.score, target.key)
+}
+
+func mergeValues(merge MergeFunc, x, y *Element) interface{} {
+	if merge == nil {
+		return x.Value
+	}
+
+	return merge(x.key, x.Value, y.Value)
+}
+
+func checkSetOperands(a, b *SkipList) error {
+	if !sameComparable(a.comparable, b.comparable) {
+		return ErrDifferentComparable
+	}
+
+	if a.duplicates || b.duplicates {
+		return ErrDuplicatesNotAllowed
+	}
+
+	return nil
+}
+
+// sameComparable returns true if c1 and c2 are the same comparable.
+// Comparables like GreaterThanFunc are funcs which cannot be compared by ==.
+// They're the same if they point to the same func.
+// Comparables of other non-comparable types are never the same, as there is no way
+// to tell whether they compare keys in the same way.
+// Pointers to them are comparable and they're the same if they point to the same value.
+func sameComparable(c1, c2 Comparable) bool {
+	t1 := reflect.TypeOf(c1)
+	t2 := reflect.TypeOf(c2)
+
+	if t1 != t2 {
+		return false
+	}
+
+	if r1, ok := c1.(reversedComparable); ok {
+		return sameComparable(r1.comparable, c2.(reversedComparable).comparable)
+	}
+
+	if t1 == nil || t1.Comparable() {
+		return equalComparable(c1, c2)
+	}
+
+	v1 := reflect.ValueOf(c1)
+	v2 := reflect.ValueOf(c2)
+
+	switch v1.Kind() {
+	case reflect.Func, reflect.Map, reflect.Slice:
+		return v1.Pointer() == v2.Pointer()
+	}
+
+	return false
+}
+
+// equalComparable returns c1 == c2.
+// A comparable type can still hold an uncomparable value in an interface field,
+// e.g. a struct embedding a GreaterThanFunc, and == panics on it.
+// Such comparables are never the same.
+func equalComparable(c1, c2 Comparable) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+
+	equal = c1 == c2
+	return
+}
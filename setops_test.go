@@ -0,0 +1,178 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestSetOperations(t *testing.T) {
+	a := assert.New(t)
+	lhs := New(Int)
+	rhs := New(Int)
+
+	for i := 0; i < 20; i += 2 {
+		lhs.Set(i, "lhs")
+	}
+
+	for i := 0; i < 30; i += 3 {
+		rhs.Set(i, "rhs")
+	}
+
+	merge := func(key, lhs, rhs interface{}) interface{} {
+		return lhs.(string) + "+" + rhs.(string)
+	}
+
+	union, err := Union(lhs, rhs, merge)
+	a.NilError(err)
+	assertSanity(a, union)
+	a.Equal(keysOf(union), []interface{}{0, 2, 3, 4, 6, 8, 9, 10, 12, 14, 15, 16, 18, 21, 24, 27})
+	a.Equal(union.MustGetValue(6), "lhs+rhs")
+	a.Equal(union.MustGetValue(8), "lhs")
+	a.Equal(union.MustGetValue(9), "rhs")
+
+	intersect, err := Intersect(lhs, rhs, merge)
+	a.NilError(err)
+	assertSanity(a, intersect)
+	a.Equal(keysOf(intersect), []interface{}{0, 6, 12, 18})
+	a.Equal(intersect.MustGetValue(12), "lhs+rhs")
+
+	intersect, err = Intersect(lhs, rhs, nil)
+	a.NilError(err)
+	a.Equal(intersect.MustGetValue(12), "lhs")
+
+	diff, err := Difference(lhs, rhs)
+	a.NilError(err)
+	assertSanity(a, diff)
+	a.Equal(keysOf(diff), []interface{}{2, 4, 8, 10, 14, 16})
+
+	diff, err = Difference(rhs, lhs)
+	a.NilError(err)
+	a.Equal(keysOf(diff), []interface{}{3, 9, 15, 21, 24, 27})
+
+	symDiff, err := SymmetricDifference(lhs, rhs)
+	a.NilError(err)
+	assertSanity(a, symDiff)
+	a.Equal(keysOf(symDiff), []interface{}{2, 3, 4, 8, 9, 10, 14, 15, 16, 21, 24, 27})
+
+	// Operands are not changed.
+	a.Equal(lhs.Len(), 10)
+	a.Equal(rhs.Len(), 10)
+	assertSanity(a, lhs)
+	assertSanity(a, rhs)
+
+	empty := New(Int)
+	union, err = Union(empty, rhs, nil)
+	a.NilError(err)
+	a.Equal(union.Len(), rhs.Len())
+	intersect, err = Intersect(lhs, empty, nil)
+	a.NilError(err)
+	a.Equal(intersect.Len(), 0)
+}
+
+func TestSetOperationsSparse(t *testing.T) {
+	a := assert.New(t)
+	dense := New(Int)
+	sparse := New(Int)
+	expected := map[int]bool{}
+
+	for i := 0; i < 10000; i++ {
+		dense.Set(i, i)
+	}
+
+	for i := 0; i < 20; i++ {
+		k := rand.Intn(20000)
+		sparse.Set(k, -k)
+
+		if k < 10000 {
+			expected[k] = true
+		}
+	}
+
+	intersect, err := Intersect(sparse, dense, nil)
+	a.NilError(err)
+	assertSanity(a, intersect)
+	a.Equal(intersect.Len(), len(expected))
+
+	for elem := intersect.Front(); elem != nil; elem = elem.Next() {
+		a.Assert(expected[elem.Key().(int)])
+		a.Equal(elem.Value, -elem.Key().(int))
+	}
+
+	diff, err := Difference(sparse, dense)
+	a.NilError(err)
+	assertSanity(a, diff)
+	a.Equal(diff.Len(), sparse.Len()-len(expected))
+
+	diff, err = Difference(dense, sparse)
+	a.NilError(err)
+	assertSanity(a, diff)
+	a.Equal(diff.Len(), dense.Len()-len(expected))
+}
+
+func TestSetOperationsInvalidOperands(t *testing.T) {
+	a := assert.New(t)
+	greaterThan := GreaterThanFunc(func(lhs, rhs interface{}) int {
+		return Int.Compare(lhs, rhs)
+	})
+	anotherGreaterThan := GreaterThanFunc(func(lhs, rhs interface{}) int {
+		return Int.Compare(lhs, rhs)
+	})
+
+	_, err := Union(New(Int), New(Uint), nil)
+	a.Equal(err, ErrDifferentComparable)
+	_, err = Intersect(New(Int), New(Reverse(Int)), nil)
+	a.Equal(err, ErrDifferentComparable)
+	_, err = Difference(New(greaterThan), New(anotherGreaterThan))
+	a.Equal(err, ErrDifferentComparable)
+	_, err = SymmetricDifference(New(greaterThan), New(greaterThan))
+	a.NilError(err)
+	_, err = Union(New(Reverse(Int)), New(Reverse(Int)), nil)
+	a.NilError(err)
+	_, err = Union(New(Reverse(greaterThan)), New(Reverse(greaterThan)), nil)
+	a.NilError(err)
+	_, err = Union(New(Reverse(greaterThan)), New(Reverse(anotherGreaterThan)), nil)
+	a.Equal(err, ErrDifferentComparable)
+	_, err = Union(New(Int), NewWithOptions(Int, Options{AllowDuplicates: true}), nil)
+	a.Equal(err, ErrDuplicatesNotAllowed)
+
+	// A struct holding a slice is not comparable. Only pointers to it can be the same.
+	tagged := taggedComparable{Comparable: Int, tags: []string{"tag"}}
+	_, err = Union(New(tagged), New(tagged), nil)
+	a.Equal(err, ErrDifferentComparable)
+	_, err = Union(New(&tagged), New(&tagged), nil)
+	a.NilError(err)
+	_, err = Union(New(&tagged), New(&taggedComparable{Comparable: Int}), nil)
+	a.Equal(err, ErrDifferentComparable)
+
+	// A struct holding a func in an interface field can be compared by == but it panics.
+	wrapped := wrappedComparable{greaterThan}
+	_, err = Union(New(wrapped), New(wrapped), nil)
+	a.Equal(err, ErrDifferentComparable)
+	a.Equal(Join(New(wrapped), New(wrapped)), ErrDifferentComparable)
+	_, err = Union(New(&wrapped), New(&wrapped), nil)
+	a.NilError(err)
+	_, err = Union(New(wrappedComparable{Int}), New(wrappedComparable{Int}), nil)
+	a.NilError(err)
+}
+
+type wrappedComparable struct {
+	Comparable
+}
+
+type taggedComparable struct {
+	Comparable
+	tags []string
+}
+
+func keysOf(list *SkipList) (keys []interface{}) {
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		keys = append(keys, elem.Key())
+	}
+
+	return
+}
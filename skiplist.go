@@ -34,7 +34,9 @@ package skiplist
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"sync/atomic"
 	"time"
 )
 
@@ -52,10 +54,74 @@ type SkipList struct {
 
 	comparable Comparable
 	rand       *rand.Rand
+	keyCodec   Codec
+	valueCodec Codec
+
+	maxLevel   int
+	level      int // Count of levels in use, which is the highest level of all elements.
+	length     int
+	back       *Element
+	duplicates bool
+	debug      bool
+	monoid     *Monoid
+	agg        *aggregator         // Maintains aggregates of elements on level pointers if it's not nil.
+	owner      *ConcurrentSkipList // The ConcurrentSkipList wrapping this list if it's not nil.
+
+	countCompares bool
+	compares      atomic.Uint64 // Count of compare calls.
+	fallthroughs  atomic.Uint64 // Count of compare calls falling through to comparable.
+
+	probability     float64
+	threshold       int32 // randLevel stops growing level if a random number is less than threshold.
+	autoMaxLevel    bool
+	autoLevel       int     // Estimated max level when autoMaxLevel is true.
+	autoLevelLength float64 // Length to increase autoLevel.
+}
 
-	maxLevel int
-	length   int
-	back     *Element
+// DefaultProbability is the default probability that an element has one more level.
+const DefaultProbability = 0.5
+
+// Options is the options to create a skip list by NewWithOptions.
+// The zero value creates the same list as New.
+type Options struct {
+	// AllowDuplicates allows elements with equal keys in the list.
+	// Elements with equal keys are kept in insertion order.
+	// See Add, GetAll, RemoveAll and Count for details.
+	AllowDuplicates bool
+
+	// Probability is the probability p that an element has one more level.
+	// It must be in (0, 1). If it's 0, DefaultProbability is used.
+	// An element has 1/(1-p) levels in average, and a search compares
+	// about log_{1/p}(N)/p elements. A smaller p like 0.25 saves memory
+	// with slightly more comparisons.
+	Probability float64
+
+	// MaxLevel is the max level of all elements.
+	// If it's 0, DefaultMaxLevel is used.
+	MaxLevel int
+
+	// AutoMaxLevel makes the effective max level grow with log_{1/p}(Len()).
+	// Elements inserted into a small list have small levels.
+	// The effective max level never exceeds MaxLevel.
+	AutoMaxLevel bool
+
+	// CountCompares counts how many times keys are compared and
+	// how many times comparing scores is not enough and Compare is called.
+	// Counters are reported by Stats. It's useful to check whether CalcScore is effective.
+	CountCompares bool
+
+	// Debug checks the comparable with the previous and next elements
+	// every time an element is inserted. If the comparable breaks any rule
+	// checked by CheckComparable, it panics with a *ComparableError.
+	// It slows down insertion and should only be used in tests.
+	Debug bool
+
+	// Monoid aggregates elements so that Aggregate returns the aggregate
+	// of elements in a key range in O(log(N)). Every level pointer caches the aggregate
+	// of elements it skips, which makes insertion and removal slower by a constant factor.
+	// Lists can only be joined if they are created with the same *Monoid.
+	// See Monoid for details.
+	Monoid *Monoid
 }
 
 // New creates a new skip list with comparable to compare keys.
@@ -63,28 +129,110 @@ type SkipList struct {
 // There are lots of pre-defined strict-typed keys like Int, Float64, String, etc.
 // We can create custom comparable by implementing Comparable interface.
 func New(comparable Comparable) *SkipList {
+	return NewWithOptions(comparable, Options{})
+}
+
+// NewWithOptions creates a new skip list with comparable to compare keys and opts.
+// It panics if opts.Probability is not in (0, 1) or opts.MaxLevel is negative.
+func NewWithOptions(comparable Comparable, opts Options) *SkipList {
 	if DefaultMaxLevel <= 0 {
 		panic("skiplist default level must not be zero or negative")
 	}
 
+	maxLevel := opts.MaxLevel
+
+	if maxLevel == 0 {
+		maxLevel = DefaultMaxLevel
+	}
+
+	if maxLevel < 0 {
+		panic(fmt.Errorf("skiplist: level must be larger than 0 (current is %v)", maxLevel))
+	}
+
+	p := opts.Probability
+
+	if p == 0 {
+		p = DefaultProbability
+	}
+
+	if !(p > 0 && p < 1) {
+		panic(fmt.Errorf("skiplist: probability must be in (0, 1) (current is %v)", p))
+	}
+
+	// Level stops growing if a random number in [0, 2^31) is less than (1-p)*2^31.
+	threshold := math.MaxInt32
+
+	if t := (1 - p) * (1 << 31); t < math.MaxInt32 {
+		threshold = int(t)
+	}
+
 	source := rand.NewSource(time.Now().UnixNano())
-	return &SkipList{
+	list := &SkipList{
 		elementHeader: elementHeader{
-			levels: make([]*Element, DefaultMaxLevel),
+			levels: make([]*Element, maxLevel),
+			spans:  make([]int, maxLevel),
 		},
 
 		comparable: comparable,
 		rand:       rand.New(source),
 
-		maxLevel: DefaultMaxLevel,
+		maxLevel:   maxLevel,
+		duplicates: opts.AllowDuplicates,
+		debug:      opts.Debug,
+
+		countCompares: opts.CountCompares,
+
+		probability:  p,
+		threshold:    int32(threshold),
+		autoMaxLevel: opts.AutoMaxLevel,
+	}
+	list.resetAutoLevel()
+
+	if opts.Monoid != nil {
+		list.monoid = opts.Monoid
+		list.setAggregator(newMonoidAggregator(opts.Monoid))
+	}
+
+	return list
+}
+
+// Options returns the options of the list.
+// The MaxLevel is the current max level which may be changed by SetMaxLevel.
+func (list *SkipList) Options() Options {
+	return Options{
+		AllowDuplicates: list.duplicates,
+		Probability:     list.probability,
+		MaxLevel:        list.maxLevel,
+		AutoMaxLevel:    list.autoMaxLevel,
+		CountCompares:   list.countCompares,
+		Debug:           list.debug,
+		Monoid:          list.monoid,
 	}
 }
 
 // Init resets the list and discards all existing elements.
+// Discarded elements are detached from the list as if they were removed.
+//
+// The complexity is O(N).
 func (list *SkipList) Init() *SkipList {
+	for elem := list.Front(); elem != nil; {
+		next := elem.Next()
+		elem.reset()
+		elem = next
+	}
+
+	return list.clear()
+}
+
+// clear resets the list header without touching any element.
+func (list *SkipList) clear() *SkipList {
 	list.back = nil
+	list.level = 0
 	list.length = 0
+	list.resetAutoLevel()
 	list.levels = make([]*Element, len(list.levels))
+	list.spans = make([]int, len(list.spans))
+	list.resetAggregates(&list.elementHeader)
 	return list
 }
 
@@ -120,10 +268,28 @@ func (list *SkipList) Len() int {
 
 // Set sets value for the key.
 // If the key exists, updates element's value.
+// If duplicates are allowed and there are elements with the key, updates the first one.
 // Returns the element holding the key and value.
 //
 // The complexity is O(log(N)).
 func (list *SkipList) Set(key, value interface{}) (elem *Element) {
+	return list.insert(key, value, false)
+}
+
+// Add adds a new element for the key even if there are elements with the key.
+// The new element is placed after all elements with the key.
+// Returns the new element.
+//
+// If duplicates are not allowed, Add is the same as Set.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Add(key, value interface{}) (elem *Element) {
+	return list.insert(key, value, list.duplicates)
+}
+
+// insert inserts a new element for the key.
+// If add is false, the value of the first element with the key is updated if any.
+func (list *SkipList) insert(key, value interface{}, add bool) (elem *Element) {
 	score := list.calcScore(key)
 
 	// Happy path for empty list.
@@ -133,44 +299,92 @@ func (list *SkipList) Set(key, value interface{}) (elem *Element) {
 
 		for i := 0; i < level; i++ {
 			list.levels[i] = elem
+			list.spans[i] = 1
 		}
 
 		list.back = elem
+		list.level = level
 		list.length++
+
+		if list.agg != nil {
+			list.linkAggregates(elem, nil)
+		}
+
+		if list.debug {
+			list.debugCheck(elem)
+		}
+
 		return
 	}
 
-	// Find out previous elements on every possible levels.
-	max := len(list.levels)
-	prevHeader := &list.elementHeader
+	var path searchPath
+	prevElemHeaders, prevRanks := path.alloc(len(list.levels))
 
-	var maxStaticAllocElemHeaders [preallocDefaultMaxLevel]*elementHeader
-	var prevElemHeaders []*elementHeader
+	if elem = list.search(score, key, add, prevElemHeaders, prevRanks); elem != nil {
+		elem.Value = value
+		list.UpdateAggregates(elem)
+		return
+	}
+
+	elem = list.link(score, key, value, prevElemHeaders, prevRanks)
+	return
+}
 
+// searchPath holds previous elements and their ranks on every level
+// collected by search. It's allocated on stack when levels are not too many.
+type searchPath struct {
+	maxStaticAllocElemHeaders [preallocDefaultMaxLevel]*elementHeader
+	maxStaticAllocRanks       [preallocDefaultMaxLevel]int
+}
+
+func (path *searchPath) alloc(max int) (prevElemHeaders []*elementHeader, prevRanks []int) {
 	if max <= preallocDefaultMaxLevel {
-		prevElemHeaders = maxStaticAllocElemHeaders[:max]
+		prevElemHeaders = path.maxStaticAllocElemHeaders[:max]
+		prevRanks = path.maxStaticAllocRanks[:max]
 	} else {
 		prevElemHeaders = make([]*elementHeader, max)
+		prevRanks = make([]int, max)
 	}
 
+	return
+}
+
+// search finds out previous elements of a new element for the key on every level
+// and stores them in prevElemHeaders along with their ranks in prevRanks.
+// The list header's rank is 0 and the first element's rank is 1.
+//
+// If add is false and there is an element with the key, returns the first element with the key.
+// In this case, prevElemHeaders and prevRanks may be incomplete.
+// If add is true, the new element is placed after all elements with the key.
+func (list *SkipList) search(score float64, key interface{}, add bool, prevElemHeaders []*elementHeader, prevRanks []int) (elem *Element) {
+	max := len(list.levels)
+	prevHeader := &list.elementHeader
+
+	// Rank of prevHeader.
+	rank := 0
+
 	for i := max - 1; i >= 0; {
 		prevElemHeaders[i] = prevHeader
+		prevRanks[i] = rank
 
 		for next := prevHeader.levels[i]; next != nil; next = prevHeader.levels[i] {
-			if comp := list.compare(score, key, next); comp <= 0 {
-				// Find the elem with the same key.
-				// Update value and return the elem.
-				if comp == 0 {
-					elem = next
-					elem.Value = value
-					return
-				}
+			comp := list.compare(score, key, next)
 
+			// Find the elem with the same key.
+			if comp == 0 && !list.duplicates {
+				elem = next
+				return
+			}
+
+			// Move forward to the last element with the key when adding.
+			if comp < 0 || comp == 0 && !add {
 				break
 			}
 
+			rank += prevHeader.spans[i]
 			prevHeader = &next.elementHeader
 			prevElemHeaders[i] = prevHeader
+			prevRanks[i] = rank
 		}
 
 		// Skip levels if they point to the same element as topLevel.
@@ -178,27 +392,73 @@ func (list *SkipList) Set(key, value interface{}) (elem *Element) {
 
 		for i--; i >= 0 && prevHeader.levels[i] == topLevel; i-- {
 			prevElemHeaders[i] = prevHeader
+			prevRanks[i] = rank
 		}
 	}
 
-	// Create a new element.
+	// Find the first element with the key if any.
+	if list.duplicates && !add {
+		if next := prevElemHeaders[0].levels[0]; next != nil && list.compare(score, key, next) == 0 {
+			elem = next
+			return
+		}
+	}
+
+	return
+}
+
+// link creates a new element and links it after prevElemHeaders collected by search.
+func (list *SkipList) link(score float64, key, value interface{}, prevElemHeaders []*elementHeader, prevRanks []int) (elem *Element) {
 	level := list.randLevel()
 	elem = newElement(list, level, score, key, value)
+	list.linkElement(elem, prevElemHeaders, prevRanks)
+	return
+}
+
+// linkElement links elem after prevElemHeaders collected by search.
+// All pointers and spans of elem are overwritten.
+func (list *SkipList) linkElement(elem *Element, prevElemHeaders []*elementHeader, prevRanks []int) {
+	level := elem.Level()
 
 	// Set up prev element.
+	elem.prev = nil
+
 	if prev := prevElemHeaders[0]; prev != &list.elementHeader {
 		elem.prev = prev.Element()
 	}
 
 	// Set up prevTopLevel.
+	elem.prevTopLevel = nil
+
 	if prev := prevElemHeaders[level-1]; prev != &list.elementHeader {
 		elem.prevTopLevel = prev.Element()
 	}
 
-	// Set up levels.
+	// Set up levels and spans.
+	rank := prevRanks[0] + 1
+
 	for i := 0; i < level; i++ {
-		elem.levels[i] = prevElemHeaders[i].levels[i]
-		prevElemHeaders[i].levels[i] = elem
+		prev := prevElemHeaders[i]
+		elem.levels[i] = prev.levels[i]
+		elem.spans[i] = 0
+
+		if elem.levels[i] != nil {
+			elem.spans[i] = prevRanks[i] + prev.spans[i] + 1 - rank
+		}
+
+		prev.levels[i] = elem
+		prev.spans[i] = rank - prevRanks[i]
+	}
+
+	// Higher levels in use skip one more element.
+	for i := level; i < list.level; i++ {
+		if prev := prevElemHeaders[i]; prev.levels[i] != nil {
+			prev.spans[i]++
+		}
+	}
+
+	if level > list.level {
+		list.level = level
 	}
 
 	// Find out the largest level with next element.
@@ -233,7 +493,14 @@ func (list *SkipList) Set(key, value interface{}) (elem *Element) {
 	}
 
 	list.length++
-	return
+
+	if list.agg != nil {
+		list.linkAggregates(elem, prevElemHeaders)
+	}
+
+	if list.debug {
+		list.debugCheck(elem)
+	}
 }
 
 func (list *SkipList) findNext(start *Element, score float64, key interface{}) (elem *Element) {
@@ -266,7 +533,9 @@ func (list *SkipList) findNext(start *Element, score float64, key interface{}) (
 		for next := prevHeader.levels[i]; next != nil; next = prevHeader.levels[i] {
 			if comp := list.compare(score, key, next); comp <= 0 {
 				elem = next
-				if comp == 0 {
+
+				// Keep searching the first element with the key if duplicates are allowed.
+				if comp == 0 && !list.duplicates {
 					return
 				}
 
@@ -304,8 +573,122 @@ func (list *SkipList) Find(key interface{}) (elem *Element) {
 	return list.FindNext(nil, key)
 }
 
+// findPrev returns the last element that is less than key.
+// If inclusive is true, the element equal to key can be returned.
+func (list *SkipList) findPrev(score float64, key interface{}, inclusive bool) (elem *Element) {
+	if list.length == 0 {
+		return
+	}
+
+	// The comp must be greater than or equal to limit to move forward.
+	limit := 1
+
+	if inclusive {
+		limit = 0
+	}
+
+	if list.compare(score, key, list.Front()) < limit {
+		return
+	}
+	if list.compare(score, key, list.Back()) >= limit {
+		elem = list.Back()
+		return
+	}
+
+	prevHeader := &list.elementHeader
+	i := len(prevHeader.levels) - 1
+
+	for i >= 0 {
+		for next := prevHeader.levels[i]; next != nil; next = prevHeader.levels[i] {
+			if list.compare(score, key, next) < limit {
+				break
+			}
+
+			prevHeader = &next.elementHeader
+		}
+
+		topLevel := prevHeader.levels[i]
+
+		// Skip levels if they point to the same element as topLevel.
+		for i--; i >= 0 && prevHeader.levels[i] == topLevel; i-- {
+		}
+	}
+
+	if prevHeader != &list.elementHeader {
+		elem = prevHeader.Element()
+	}
+
+	return
+}
+
+// FindPrev returns the last element before start that is less or equal to key.
+// If start is less or equal to key, returns start.
+// If there is no such element, returns nil.
+// If start is nil, find element from back.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) FindPrev(start *Element, key interface{}) (elem *Element) {
+	score := list.calcScore(key)
+
+	if start != nil && list.compare(score, key, start) >= 0 {
+		elem = start
+		return
+	}
+
+	// All elements less or equal to key are before start.
+	return list.findPrev(score, key, true)
+}
+
+// Floor returns the last element that is less or equal to key.
+// If there is no such element, returns nil.
+// If duplicates are allowed, returns the last element with the key.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Floor(key interface{}) (elem *Element) {
+	return list.findPrev(list.calcScore(key), key, true)
+}
+
+// Ceiling returns the first element that is greater or equal to key.
+// It's the same as Find.
+// If there is no such element, returns nil.
+// If duplicates are allowed, returns the first element with the key.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Ceiling(key interface{}) (elem *Element) {
+	return list.FindNext(nil, key)
+}
+
+// Lower returns the last element that is strictly less than key.
+// If there is no such element, returns nil.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Lower(key interface{}) (elem *Element) {
+	return list.findPrev(list.calcScore(key), key, false)
+}
+
+// Higher returns the first element that is strictly greater than key.
+// If there is no such element, returns nil.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Higher(key interface{}) (elem *Element) {
+	return list.higher(list.calcScore(key), key)
+}
+
+func (list *SkipList) higher(score float64, key interface{}) (elem *Element) {
+	prev := list.findPrev(score, key, true)
+
+	if prev == nil {
+		elem = list.Front()
+		return
+	}
+
+	elem = prev.Next()
+	return
+}
+
 // Get returns an element with the key.
 // If the key is not found, returns nil.
+// If duplicates are allowed, returns the first element with the key.
 //
 // The complexity is O(log(N)).
 func (list *SkipList) Get(key interface{}) (elem *Element) {
@@ -356,6 +739,7 @@ func (list *SkipList) MustGetValue(key interface{}) interface{} {
 
 // Remove removes an element.
 // Returns removed element pointer if found, nil if it's not found.
+// If duplicates are allowed, removes the first element with the key.
 //
 // The complexity is O(log(N)).
 func (list *SkipList) Remove(key interface{}) (elem *Element) {
@@ -369,9 +753,52 @@ func (list *SkipList) Remove(key interface{}) (elem *Element) {
 	return
 }
 
+// GetAll returns all elements with the key in insertion order.
+// If the key is not found, returns nil.
+//
+// The complexity is O(log(N)+M), where M is the count of returned elements.
+func (list *SkipList) GetAll(key interface{}) (elems []*Element) {
+	score := list.calcScore(key)
+
+	for elem := list.findNext(nil, score, key); elem != nil && list.compare(score, key, elem) == 0; elem = elem.Next() {
+		elems = append(elems, elem)
+	}
+
+	return
+}
+
+// RemoveAll removes all elements with the key.
+// Returns removed elements in insertion order.
+//
+// The complexity is O(M*log(N)), where M is the count of removed elements.
+func (list *SkipList) RemoveAll(key interface{}) (elems []*Element) {
+	elems = list.GetAll(key)
+
+	for _, elem := range elems {
+		list.RemoveElement(elem)
+	}
+
+	return
+}
+
+// Count returns the count of elements with the key.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Count(key interface{}) int {
+	score := list.calcScore(key)
+	first := list.findNext(nil, score, key)
+
+	if first == nil || list.compare(score, key, first) != 0 {
+		return 0
+	}
+
+	last := list.findPrev(score, key, true)
+	return last.Index() - first.Index() + 1
+}
+
 // RemoveFront removes front element node and returns the removed element.
 //
-// The complexity is O(1).
+// The complexity is O(log(N)).
 func (list *SkipList) RemoveFront() (front *Element) {
 	if list.length == 0 {
 		return
@@ -403,31 +830,51 @@ func (list *SkipList) RemoveElement(elem *Element) {
 		return
 	}
 
-	level := elem.Level()
-
-	// Find out all previous elements.
-	max := 0
-	prevElems := make([]*Element, level)
-	prev := elem.prev
+	list.unlink(elem)
+	elem.reset()
+}
 
-	for prev != nil && max < level {
-		prevLevel := len(prev.levels)
+// unlink removes elem from all levels of the list.
+// Pointers of elem are left unchanged so that elem can be linked again.
+func (list *SkipList) unlink(elem *Element) {
+	level := elem.Level()
+	top := list.level
 
-		for ; max < prevLevel && max < level; max++ {
-			prevElems[max] = prev
-		}
+	// Find out all previous elements on every level in use.
+	// Elements on levels higher than elem's level are required to adjust spans.
+	var maxStaticAllocElems [preallocDefaultMaxLevel]*Element
+	var prevElems []*Element
 
-		for prev = prev.prevTopLevel; prev != nil && prev.Level() == prevLevel; prev = prev.prevTopLevel {
-		}
+	if top <= preallocDefaultMaxLevel {
+		prevElems = maxStaticAllocElems[:top]
+	} else {
+		prevElems = make([]*Element, top)
 	}
 
+	max := list.findPrevElems(elem, prevElems)
+
 	// Adjust prev elements which point to elem directly.
-	for i := 0; i < max; i++ {
+	for i := 0; i < max && i < level; i++ {
 		prevElems[i].levels[i] = elem.levels[i]
+		prevElems[i].spans[i] += elem.spans[i] - 1
 	}
 
 	for i := max; i < level; i++ {
 		list.levels[i] = elem.levels[i]
+		list.spans[i] += elem.spans[i] - 1
+	}
+
+	// Higher levels in use skip one less element.
+	for i := level; i < top; i++ {
+		prevHeader := &list.elementHeader
+
+		if i < max {
+			prevHeader = &prevElems[i].elementHeader
+		}
+
+		if prevHeader.levels[i] != nil {
+			prevHeader.spans[i]--
+		}
 	}
 
 	// Adjust prev and prevTopLevel of next elements.
@@ -452,7 +899,97 @@ func (list *SkipList) RemoveElement(elem *Element) {
 	}
 
 	list.length--
-	elem.reset()
+
+	if list.agg != nil {
+		list.updatePrevAggregates(prevElems[:max])
+	}
+
+	list.trimLevel()
+}
+
+// trimLevel decreases list.level to the highest level pointing to an element.
+func (list *SkipList) trimLevel() {
+	for list.level > 0 && list.levels[list.level-1] == nil {
+		list.level--
+	}
+}
+
+// findPrevElems finds out the previous element of elem on every level and stores them in prevElems.
+// Returns the count of levels with a previous element.
+// The list header is previous to elem on the rest levels.
+func (list *SkipList) findPrevElems(elem *Element, prevElems []*Element) (max int) {
+	top := len(prevElems)
+	prev := elem.prev
+
+	for prev != nil && max < top {
+		prevLevel := len(prev.levels)
+
+		for ; max < prevLevel && max < top; max++ {
+			prevElems[max] = prev
+		}
+
+		for prev = prev.prevTopLevel; prev != nil && prev.Level() == prevLevel; prev = prev.prevTopLevel {
+		}
+	}
+
+	return
+}
+
+// GetByIndex returns the element at the 0-based index.
+// If index is out of range, returns nil.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) GetByIndex(index int) (elem *Element) {
+	if index < 0 || index >= list.length {
+		return
+	}
+
+	target := index + 1
+	rank := 0
+	prevHeader := &list.elementHeader
+
+	for i := len(prevHeader.levels) - 1; i >= 0; i-- {
+		for next := prevHeader.levels[i]; next != nil && rank+prevHeader.spans[i] <= target; next = prevHeader.levels[i] {
+			rank += prevHeader.spans[i]
+			prevHeader = &next.elementHeader
+		}
+
+		if rank == target {
+			elem = prevHeader.Element()
+			return
+		}
+	}
+
+	return
+}
+
+// IndexOf returns the 0-based index of the element with the key.
+// If the key is not found, returns -1.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) IndexOf(key interface{}) int {
+	elem := list.Get(key)
+
+	if elem == nil {
+		return -1
+	}
+
+	return elem.Index()
+}
+
+// RemoveByIndex removes the element at the 0-based index and returns the removed element.
+// If index is out of range, returns nil.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) RemoveByIndex(index int) (elem *Element) {
+	elem = list.GetByIndex(index)
+
+	if elem == nil {
+		return
+	}
+
+	list.RemoveElement(elem)
+	return
 }
 
 // MaxLevel returns current max level value.
@@ -477,34 +1014,48 @@ func (list *SkipList) SetMaxLevel(level int) (old int) {
 	if old > level {
 		for i := old - 1; i >= level; i-- {
 			if list.levels[i] != nil {
-				level = i
+				level = i + 1
 				break
 			}
 		}
 
 		list.levels = list.levels[:level]
+		list.spans = list.spans[:level]
+		list.resizeAggregates()
 		return
 	}
 
 	if level <= cap(list.levels) {
 		list.levels = list.levels[:level]
+		list.spans = list.spans[:level]
+		list.resizeAggregates()
 		return
 	}
 
 	levels := make([]*Element, level)
 	copy(levels, list.levels)
 	list.levels = levels
+
+	spans := make([]int, level)
+	copy(spans, list.spans)
+	list.spans = spans
+	list.resizeAggregates()
 	return
 }
 
 func (list *SkipList) randLevel() int {
 	estimated := list.maxLevel
-	const prob = 1 << 30 // Half of 2^31.
+
+	if list.autoMaxLevel {
+		estimated = list.estimateLevel()
+	}
+
+	threshold := list.threshold
 	rand := list.rand
 	i := 1
 
 	for ; i < estimated; i++ {
-		if rand.Int31() < prob {
+		if rand.Int31() < threshold {
 			break
 		}
 	}
@@ -512,8 +1063,38 @@ func (list *SkipList) randLevel() int {
 	return i
 }
 
+// estimateLevel returns floor(log_{1/p}(Len()))+1 capped by max level.
+// The estimated level only grows when list length grows.
+func (list *SkipList) estimateLevel() int {
+	for list.autoLevel < list.maxLevel && float64(list.length) >= list.autoLevelLength {
+		list.autoLevel++
+		list.autoLevelLength /= list.probability
+	}
+
+	if list.autoLevel > list.maxLevel {
+		return list.maxLevel
+	}
+
+	return list.autoLevel
+}
+
+func (list *SkipList) resetAutoLevel() {
+	list.autoLevel = 1
+	list.autoLevelLength = 1 / list.probability
+}
+
 // compare compares value of two elements and returns -1, 0 and 1.
 func (list *SkipList) compare(score float64, key interface{}, rhs *Element) int {
+	if list.countCompares {
+		list.countCompare(score, rhs)
+	}
+
+	return list.compareUncounted(score, key, rhs)
+}
+
+// compareUncounted compares key with rhs in the same way as compare
+// without counting the comparison in Stats.
+func (list *SkipList) compareUncounted(score float64, key interface{}, rhs *Element) int {
 	if score != rhs.score {
 		if score > rhs.score {
 			return 1
@@ -270,6 +270,369 @@ func TestRandomList(t *testing.T) {
 	assertSanity(a, list)
 }
 
+func TestIndex(t *testing.T) {
+	a := assert.New(t)
+
+	const seed = 0x7d3a91c4
+	const N = 10000
+	rnd := rand.New(rand.NewSource(seed))
+	list := New(Int)
+	list.SetRandSource(rand.NewSource(seed))
+
+	a.Equal(list.GetByIndex(0), nil)
+	a.Equal(list.IndexOf(1), -1)
+	a.Equal(list.RemoveByIndex(0), nil)
+
+	for i := 0; i < N; i++ {
+		list.Set(rnd.Intn(N*2), i)
+	}
+
+	for i := 0; i < N/2; i++ {
+		list.Remove(rnd.Intn(N * 2))
+	}
+
+	assertSanity(a, list)
+
+	index := 0
+
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		a.Use(&index)
+		a.Equal(list.GetByIndex(index), elem)
+		a.Equal(elem.Index(), index)
+		a.Equal(list.IndexOf(elem.Key()), index)
+		index++
+	}
+
+	a.Equal(list.GetByIndex(-1), nil)
+	a.Equal(list.GetByIndex(list.Len()), nil)
+	a.Equal(list.IndexOf(-1), -1)
+
+	for list.Len() > 0 {
+		index := rnd.Intn(list.Len())
+		expected := list.GetByIndex(index)
+		removed := list.RemoveByIndex(index)
+		a.Equal(removed, expected)
+		a.Equal(removed.Index(), -1)
+
+		if list.Len()%1000 == 0 {
+			assertSanity(a, list)
+		}
+	}
+
+	a.Equal(list.Front(), nil)
+}
+
+func TestSetMaxLevelShrink(t *testing.T) {
+	a := assert.New(t)
+
+	const seed = 0x5e7a11
+	const N = 1000
+	list := New(Int)
+	list.SetRandSource(rand.NewSource(seed))
+
+	for i := 0; i < N; i++ {
+		list.Set(i, i)
+	}
+
+	top := 0
+
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		top = max(top, elem.Level())
+	}
+
+	a.Assert(top > 2)
+
+	// Levels used by existing elements are kept in the list header.
+	list.SetMaxLevel(top - 2)
+	a.Equal(list.MaxLevel(), top-2)
+	a.Equal(len(list.levels), top)
+	a.NilError(list.Validate())
+
+	checkIndexes := func() {
+		index := 0
+
+		for elem := list.Front(); elem != nil; elem = elem.Next() {
+			a.Use(&index)
+			a.Equal(elem.Index(), index)
+			a.Equal(list.GetByIndex(index), elem)
+			index++
+		}
+	}
+	checkIndexes()
+
+	for i := 0; i < N; i += 3 {
+		list.Remove(i)
+	}
+
+	for i := N; i < N*2; i++ {
+		elem := list.Set(i, i)
+		a.Assert(elem.Level() <= top-2)
+	}
+
+	a.NilError(list.Validate())
+	checkIndexes()
+}
+
+func TestNavigation(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	a.Equal(list.Floor(0), nil)
+	a.Equal(list.Ceiling(0), nil)
+	a.Equal(list.Lower(0), nil)
+	a.Equal(list.Higher(0), nil)
+	a.Equal(list.FindPrev(nil, 0), nil)
+
+	const seed = 0x1b3f5e27
+	const N = 1000
+	rnd := rand.New(rand.NewSource(seed))
+	keys := map[int]bool{}
+
+	for i := 0; i < N; i++ {
+		key := rnd.Intn(N*4) * 2 // All keys are even.
+		list.Set(key, i)
+		keys[key] = true
+	}
+
+	assertSanity(a, list)
+
+	keyOf := func(elem *Element) interface{} {
+		if elem == nil {
+			return nil
+		}
+
+		return elem.Key()
+	}
+	search := func(key, step int, inclusive bool) interface{} {
+		if !inclusive {
+			key += step
+		}
+
+		for ; key >= -2 && key <= N*8+2; key += step {
+			if keys[key] {
+				return key
+			}
+		}
+
+		return nil
+	}
+
+	for key := -2; key <= N*8+2; key++ {
+		a.Use(&key)
+		a.Equal(keyOf(list.Floor(key)), search(key, -1, true))
+		a.Equal(keyOf(list.Ceiling(key)), search(key, 1, true))
+		a.Equal(keyOf(list.Lower(key)), search(key, -1, false))
+		a.Equal(keyOf(list.Higher(key)), search(key, 1, false))
+		a.Equal(list.FindPrev(nil, key), list.Floor(key))
+	}
+
+	front := list.Front()
+	back := list.Back()
+	a.Equal(list.FindPrev(front, -1), nil)
+	a.Equal(list.FindPrev(front, N*10), front)
+	a.Equal(list.FindPrev(back, N*10), back)
+	a.Equal(list.FindPrev(back, back.Key().(int)-1), back.Prev())
+	a.Equal(list.Floor(back.Key()), back)
+	a.Equal(list.Lower(front.Key()), nil)
+	a.Equal(list.Higher(back.Key()), nil)
+}
+
+func TestDuplicates(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{AllowDuplicates: true})
+
+	a.Equal(list.Count(1), 0)
+	a.Equal(list.GetAll(1), nil)
+	a.Equal(list.RemoveAll(1), nil)
+
+	e1 := list.Add(10, "a")
+	e2 := list.Add(10, "b")
+	e3 := list.Add(10, "c")
+	list.Add(5, "x")
+	list.Add(20, "y")
+
+	a.Equal(list.Len(), 5)
+	a.Equal(list.Count(10), 3)
+	a.Equal(list.Count(5), 1)
+	a.Equal(list.Count(7), 0)
+	a.Equal(list.GetAll(10), []*Element{e1, e2, e3})
+	a.Equal(list.Get(10), e1)
+	a.Equal(list.Find(10), e1)
+	a.Equal(list.Ceiling(10), e1)
+	a.Equal(list.Floor(10), e3)
+	a.Equal(list.Higher(10).Key(), 20)
+	a.Equal(list.Lower(10).Key(), 5)
+	a.Equal(list.IndexOf(10), 1)
+	assertSanity(a, list)
+
+	// Set updates the first element with the key.
+	a.Equal(list.Set(10, "A"), e1)
+	a.Equal(e1.Value, "A")
+	a.Equal(list.Len(), 5)
+
+	// Set inserts a new element if the key doesn't exist.
+	e4 := list.Set(15, "z")
+	a.Equal(e4.Prev(), e3)
+	a.Equal(list.Len(), 6)
+
+	// Range with exclusive from skips all elements with the key.
+	it := list.Range(10, nil, &RangeOptions{ExcludeFrom: true})
+	a.Equal(it.Next(), e4)
+
+	a.Equal(list.Remove(10), e1)
+	a.Equal(list.GetAll(10), []*Element{e2, e3})
+	a.Equal(list.RemoveAll(10), []*Element{e2, e3})
+	a.Equal(list.Count(10), 0)
+	a.Equal(list.Len(), 3)
+	assertSanity(a, list)
+
+	// Lots of duplicates.
+	rnd := rand.New(rand.NewSource(0x3c6ef372))
+	list.Init()
+
+	for i := 0; i < 10000; i++ {
+		list.Add(rnd.Intn(100), i)
+	}
+
+	assertSanity(a, list)
+
+	for key := 0; key < 100; key++ {
+		elems := list.GetAll(key)
+		a.Use(&key)
+		a.Equal(list.Count(key), len(elems))
+
+		// Insertion order is kept.
+		for i := 1; i < len(elems); i++ {
+			a.Assert(elems[i-1].Value.(int) < elems[i].Value.(int))
+		}
+	}
+
+	// Add in a list without duplicates is the same as Set.
+	unique := New(Int)
+	unique.Add(1, "a")
+	unique.Add(1, "b")
+	a.Equal(unique.Len(), 1)
+	a.Equal(unique.Front().Value, "b")
+}
+
+func TestDuplicatesWithIteration(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{AllowDuplicates: true})
+
+	for i := 0; i < 3; i++ {
+		list.Add(1, i)
+		list.Add(2, i)
+		list.Add(3, i)
+	}
+
+	values := []interface{}{}
+
+	for k, v := range list.All() {
+		values = append(values, k.(int)*10+v.(int))
+		list.Remove(k)
+	}
+
+	a.Equal(values, []interface{}{10, 11, 12, 20, 21, 22, 30, 31, 32})
+	a.Equal(list.Len(), 0)
+
+	for i := 0; i < 3; i++ {
+		list.Add(1, i)
+		list.Add(2, i)
+	}
+
+	values = values[:0]
+
+	for k, v := range list.Backward() {
+		values = append(values, k.(int)*10+v.(int))
+		list.RemoveElement(list.Floor(k))
+	}
+
+	a.Equal(values, []interface{}{22, 21, 20, 12, 11, 10})
+
+	// Serialization keeps duplicates.
+	list.Add(1, "a")
+	list.Add(1, "b")
+	data, err := list.MarshalBinary()
+	a.NilError(err)
+	loaded := NewWithOptions(Int, Options{AllowDuplicates: true})
+	a.NilError(loaded.UnmarshalBinary(data))
+	a.Equal(loaded.Count(1), 2)
+	a.Equal(loaded.Back().Value, "b")
+	a.Assert(New(Int).UnmarshalBinary(data) != nil)
+}
+
+func TestOptions(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+	a.Equal(list.Options(), Options{
+		Probability: DefaultProbability,
+		MaxLevel:    DefaultMaxLevel,
+	})
+
+	const N = 10000
+	list = NewWithOptions(Int, Options{
+		Probability: 0.25,
+		MaxLevel:    6,
+	})
+	a.Equal(len(list.levels), 6)
+
+	for i := 0; i < N; i++ {
+		list.Set(i, i)
+	}
+
+	assertSanity(a, list)
+	stats := list.levelStats()
+	a.Assert(stats.MaxLevel <= 6)
+
+	// An element has 1/(1-p) levels in average.
+	avg := float64(stats.Pointers) / N
+	a.Use(&avg)
+	a.Assert(avg > 1.25 && avg < 1.42)
+
+	right := list.SplitAt(N / 2)
+	a.Equal(right.Options(), list.Options())
+
+	list = NewWithOptions(Int, Options{
+		AutoMaxLevel: true,
+	})
+
+	for i := 0; i < N; i++ {
+		list.Set(i, i)
+	}
+
+	assertSanity(a, list)
+	stats = list.levelStats()
+	a.Use(&stats)
+	a.Assert(stats.MaxLevel <= 14) // floor(log2(N))+1 = 14.
+
+	// The first element is always added with level 1.
+	a.Equal(list.Front().Level(), 1)
+
+	a.Equal(list.estimateLevel(), 14)
+	list.SetMaxLevel(8)
+	a.Equal(list.estimateLevel(), 8)
+	list.Init()
+	a.Equal(list.estimateLevel(), 1)
+
+	for _, p := range []float64{-1, 1, 2, math.NaN()} {
+		func() {
+			defer func() {
+				a.Assert(recover() != nil)
+			}()
+
+			NewWithOptions(Int, Options{Probability: p})
+		}()
+	}
+
+	func() {
+		defer func() {
+			a.Assert(recover() != nil)
+		}()
+
+		NewWithOptions(Int, Options{MaxLevel: -1})
+	}()
+}
+
 func BenchmarkDefaultWorstInserts(b *testing.B) {
 	list := New(Int)
 
@@ -316,6 +679,51 @@ func BenchmarkRandomSelect(b *testing.B) {
 	}
 }
 
+func BenchmarkOptions(b *testing.B) {
+	cases := []struct {
+		name string
+		opts Options
+	}{
+		{"p=1/2", Options{}},
+		{"p=1/4", Options{Probability: 0.25}},
+		{"p=1/2,auto", Options{AutoMaxLevel: true}},
+		{"p=1/4,auto", Options{Probability: 0.25, AutoMaxLevel: true}},
+	}
+
+	const N = 100000
+	keys := rand.New(rand.NewSource(0x5eed)).Perm(N)
+
+	for _, c := range cases {
+		b.Run(c.name+"/Set", func(b *testing.B) {
+			var list *SkipList
+
+			for i := 0; i < b.N; i++ {
+				if i%N == 0 {
+					list = NewWithOptions(Int, c.opts)
+				}
+
+				list.Set(keys[i%N], i)
+			}
+
+			b.ReportMetric(float64(list.levelStats().Pointers)/float64(list.Len()), "levels/elem")
+		})
+
+		b.Run(c.name+"/Get", func(b *testing.B) {
+			list := NewWithOptions(Int, c.opts)
+
+			for _, key := range keys {
+				list.Set(key, key)
+			}
+
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				list.Get(keys[i%N])
+			}
+		})
+	}
+}
+
 func ExampleSkipList() {
 	// Create a skip list with int key.
 	list := New(Int)
@@ -327,21 +735,27 @@ func ExampleSkipList() {
 
 	// Get element by index.
 	elem := list.Get(34)                // Value is stored in elem.Value.
-	fmt.Println(elem.Value)             // Output: 56
+	fmt.Println(elem.Value)             // 56
 	next := elem.Next()                 // Get next element.
 	prev := next.Prev()                 // Get previous element.
-	fmt.Println(next.Value, prev.Value) // Output: 90.12    56
+	fmt.Println(next.Value, prev.Value) // 90.12 56
 
 	// Or, directly get value just like a map
 	val, ok := list.GetValue(34)
-	fmt.Println(val, ok) // Output: 56  true
+	fmt.Println(val, ok) // 56 true
 
 	// Find first elements with score greater or equal to key
 	foundElem := list.Find(30)
-	fmt.Println(foundElem.Key(), foundElem.Value) // Output: 34 56
+	fmt.Println(foundElem.Key(), foundElem.Value) // 34 56
 
 	// Remove an element for key.
 	list.Remove(34)
+
+	// Output:
+	// 56
+	// 90.12 56
+	// 56 true
+	// 34 56
 }
 
 func ExampleGreaterThanFunc() {
@@ -364,14 +778,63 @@ func ExampleGreaterThanFunc() {
 	list.Set(T{math.Pi / 2}, "sin(π/2)")
 	list.Set(T{math.Pi}, "sin(π)")
 
-	fmt.Println(list.Front().Value) // Output: sin(π)
-	fmt.Println(list.Back().Value)  // Output: sin(π/2)
+	fmt.Println(list.Front().Value) // sin(π)
+	fmt.Println(list.Back().Value)  // sin(π/2)
 
 	// Output:
 	// sin(π)
 	// sin(π/2)
 }
 
+func TestElementRemove(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+	elems := make([]*Element, 0, 100)
+
+	for i := 0; i < 100; i++ {
+		elems = append(elems, list.Set(i, i))
+	}
+
+	for i, elem := range elems {
+		a.Equal(elem.List(), list)
+		a.Assert(elem.InList())
+
+		if i%3 == 0 {
+			a.Assert(elem.Remove())
+		}
+	}
+
+	a.Equal(list.Len(), 66)
+	assertSanity(a, list)
+
+	for i, elem := range elems {
+		if i%3 == 0 {
+			a.Assert(elem.List() == nil)
+			a.Assert(!elem.InList())
+			a.Assert(!elem.Remove())
+			a.Equal(elem.Index(), -1)
+		} else {
+			a.Assert(elem.InList())
+		}
+	}
+
+	a.Equal(list.Len(), 66)
+	assertSanity(a, list)
+
+	// Elements are detached by Init.
+	list.Init()
+	a.Equal(list.Len(), 0)
+
+	for _, elem := range elems {
+		a.Assert(!elem.InList())
+		a.Assert(!elem.Remove())
+	}
+
+	list.Set(1, "one")
+	a.Equal(list.Len(), 1)
+	assertSanity(a, list)
+}
+
 func assertSanity(a *assert.A, list *SkipList) {
 	l := list.Len()
 	maxLevel := len(list.levels) // Actual max level can be larger than list.MaxLevel().
@@ -379,6 +842,7 @@ func assertSanity(a *assert.A, list *SkipList) {
 	a.Use(&l, &cnt, &maxLevel)
 	a.Assert(l >= 0)
 	a.Assert(maxLevel >= list.MaxLevel())
+	a.NilError(list.Validate())
 
 	if l == 0 {
 		return
@@ -410,7 +874,12 @@ func assertSanity(a *assert.A, list *SkipList) {
 
 		// a.Use(&i, &score, &k1, k2)
 		a.Assert(prevScore <= score)
-		a.Assert(comp.Compare(k1, k2) < 0)
+
+		if list.duplicates {
+			a.Assert(comp.Compare(k1, k2) <= 0)
+		} else {
+			a.Assert(comp.Compare(k1, k2) < 0)
+		}
 
 		prevScore = score
 	}
@@ -438,6 +907,27 @@ func assertSanity(a *assert.A, list *SkipList) {
 			a.Equal(prev.Next(), elem)
 		}
 	}
+
+	// Spans must match distance between elements.
+	indexes := make(map[*Element]int, l)
+
+	for i, elem := range allElems {
+		indexes[elem] = i
+	}
+
+	for i := 0; i < maxLevel; i++ {
+		if next := list.levels[i]; next != nil {
+			a.Equal(list.spans[i], indexes[next]+1)
+		}
+	}
+
+	for _, elem := range allElems {
+		for i, next := range elem.levels {
+			if next != nil {
+				a.Equal(elem.spans[i], indexes[next]-indexes[elem])
+			}
+		}
+	}
 }
 
 func TestUint64(t *testing.T) {
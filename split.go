@@ -0,0 +1,251 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrJoinSelf is returned when joining a list with itself.
+var ErrJoinSelf = errors.New("skiplist: cannot join a list with itself")
+
+// SplitAt moves all elements greater or equal to key to a new list and returns the new list.
+// The new list has the same comparable, options, codecs and max level as the list.
+// Moved elements are still valid and they belong to the new list after split.
+//
+// Elements are moved by relinking pointers on every level in O(log(N)).
+// Every moved element is updated to point to the new list,
+// which costs O(M), where M is the count of moved elements.
+func (list *SkipList) SplitAt(key interface{}) (right *SkipList) {
+	right = list.cloneEmpty()
+	score := list.calcScore(key)
+
+	// Find out the last element less than key on every level.
+	max := len(list.levels)
+	prevHeader := &list.elementHeader
+	rank := 0
+
+	var path searchPath
+	prevElemHeaders, prevRanks := path.alloc(max)
+
+	for i := max - 1; i >= 0; i-- {
+		for next := prevHeader.levels[i]; next != nil && list.compare(score, key, next) > 0; next = prevHeader.levels[i] {
+			rank += prevHeader.spans[i]
+			prevHeader = &next.elementHeader
+		}
+
+		prevElemHeaders[i] = prevHeader
+		prevRanks[i] = rank
+	}
+
+	first := prevHeader.levels[0]
+
+	if first == nil {
+		return
+	}
+
+	// Move levels to the new list. The rank of the last element less than key
+	// is the count of elements staying in the list.
+	left := rank
+
+	for i := 0; i < max; i++ {
+		prev := prevElemHeaders[i]
+		next := prev.levels[i]
+
+		if next == nil {
+			continue
+		}
+
+		right.levels[i] = next
+		right.spans[i] = prevRanks[i] + prev.spans[i] - left
+		prev.levels[i] = nil
+		prev.spans[i] = 0
+
+		if list.agg != nil {
+			prev.aggregates[i] = list.agg.identity
+		}
+	}
+
+	if right.agg != nil {
+		for i := range right.levels {
+			right.updateAggregate(&right.elementHeader, i)
+		}
+	}
+
+	// The first element on every level in the new list has no previous element.
+	first.prev = nil
+
+	for i := 0; i < max; {
+		next := right.levels[i]
+
+		if next == nil {
+			break
+		}
+
+		next.prevTopLevel = nil
+		i = next.Level()
+	}
+
+	right.back = list.back
+	right.length = list.length - left
+
+	if prev := prevElemHeaders[0]; prev != &list.elementHeader {
+		list.back = prev.Element()
+	} else {
+		list.back = nil
+	}
+
+	list.length = left
+	right.level = list.level
+	list.trimLevel()
+	right.trimLevel()
+
+	for elem := first; elem != nil; elem = elem.Next() {
+		elem.list = right
+	}
+
+	return
+}
+
+// Join moves all elements in b to the back of a.
+// Every key in b must be greater than all keys in a.
+// If duplicates are allowed in a, the first key in b can be equal to the last key in a.
+// Both lists must be created with the same comparable.
+// If duplicates are not allowed in a, they must not be allowed in b either.
+// Otherwise, returns an error and both lists are not changed.
+//
+// The b is empty after join.
+// Moved elements are still valid and they belong to a after join.
+//
+// Elements are moved by relinking pointers on every level in O(log(N)).
+// Every moved element is updated to point to a,
+// which costs O(M), where M is the count of moved elements.
+func Join(a, b *SkipList) error {
+	if a == b {
+		return ErrJoinSelf
+	}
+
+	if !sameComparable(a.comparable, b.comparable) {
+		return ErrDifferentComparable
+	}
+
+	if a.monoid != b.monoid {
+		return ErrDifferentMonoid
+	}
+
+	if b.duplicates && !a.duplicates {
+		return ErrDuplicatesNotAllowed
+	}
+
+	first := b.Front()
+
+	if first == nil {
+		return nil
+	}
+
+	if a.length != 0 && !a.appendable(first.score, first.key) {
+		return fmt.Errorf("%w: key `%v` is not greater than previous key `%v`", ErrNotSorted, first.key, a.back.key)
+	}
+
+	a.growLevels(len(b.levels))
+
+	// Find out the last element on every level in a.
+	max := len(a.levels)
+	prevHeader := &a.elementHeader
+	rank := 0
+
+	var path searchPath
+	prevElemHeaders, prevRanks := path.alloc(max)
+
+	for i := max - 1; i >= 0; i-- {
+		for next := prevHeader.levels[i]; next != nil; next = prevHeader.levels[i] {
+			rank += prevHeader.spans[i]
+			prevHeader = &next.elementHeader
+		}
+
+		prevElemHeaders[i] = prevHeader
+		prevRanks[i] = rank
+	}
+
+	// Link levels of b after the last elements.
+	for i := range b.levels {
+		next := b.levels[i]
+
+		if next == nil {
+			continue
+		}
+
+		prev := prevElemHeaders[i]
+		prev.levels[i] = next
+		prev.spans[i] = a.length - prevRanks[i] + b.spans[i]
+	}
+
+	if a.agg != nil {
+		for i := range a.levels {
+			a.updateAggregate(prevElemHeaders[i], i)
+		}
+	}
+
+	// Set up prev and prevTopLevel of the first element on every level in b.
+	first.prev = a.back
+
+	for i := 0; i < len(b.levels); {
+		next := b.levels[i]
+
+		if next == nil {
+			break
+		}
+
+		i = next.Level()
+
+		if prev := prevElemHeaders[i-1]; prev != &a.elementHeader {
+			next.prevTopLevel = prev.Element()
+		}
+	}
+
+	for elem := first; elem != nil; elem = elem.Next() {
+		elem.list = a
+	}
+
+	a.back = b.back
+	a.length += b.length
+
+	if b.level > a.level {
+		a.level = b.level
+	}
+
+	b.clear()
+	return nil
+}
+
+// cloneEmpty creates an empty list with the same settings as list.
+func (list *SkipList) cloneEmpty() *SkipList {
+	clone := NewWithOptions(list.comparable, list.Options())
+	clone.keyCodec = list.keyCodec
+	clone.valueCodec = list.valueCodec
+	clone.growLevels(len(list.levels))
+
+	if list.agg != nil {
+		clone.setAggregator(list.agg)
+	}
+
+	return clone
+}
+
+// growLevels makes sure the list header has at least level levels.
+func (list *SkipList) growLevels(level int) {
+	if len(list.levels) >= level {
+		return
+	}
+
+	levels := make([]*Element, level)
+	copy(levels, list.levels)
+	list.levels = levels
+
+	spans := make([]int, level)
+	copy(spans, list.spans)
+	list.spans = spans
+	list.resizeAggregates()
+}
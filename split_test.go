@@ -0,0 +1,140 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestSplitAt(t *testing.T) {
+	a := assert.New(t)
+	const N = 1000
+
+	for _, key := range []int{-1, 0, 1, 2*N/3 + 1, N / 2, 2*N - 2, 2 * N} {
+		list := New(Int)
+		elems := make([]*Element, 0, N)
+
+		for i := 0; i < N; i++ {
+			elems = append(elems, list.Set(i*2, i))
+		}
+
+		right := list.SplitAt(key)
+		assertSanity(a, list)
+		assertSanity(a, right)
+		a.Equal(list.Len()+right.Len(), N)
+		a.Equal(right.MaxLevel(), list.MaxLevel())
+
+		for i, elem := range elems {
+			if i*2 < key {
+				a.Assert(elem.list == list)
+				a.Equal(elem.Index(), i)
+			} else {
+				a.Assert(elem.list == right)
+				a.Equal(elem.Index(), i-list.Len())
+			}
+		}
+
+		// Both lists are still functional.
+		list.Set(key-1, "left")
+		right.Set(key+N*3, "right")
+		assertSanity(a, list)
+		assertSanity(a, right)
+
+		if front := right.Front(); front != nil {
+			right.RemoveElement(front)
+			assertSanity(a, right)
+		}
+
+		if back := list.Back(); back != nil {
+			list.RemoveElement(back)
+			assertSanity(a, list)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	a := assert.New(t)
+	const N = 1000
+
+	for _, size := range []int{0, 1, N / 3, N - 1, N} {
+		list := New(Int)
+		elems := make([]*Element, 0, N)
+
+		for i := 0; i < N; i++ {
+			elems = append(elems, list.Set(i, i))
+		}
+
+		right := list.SplitAt(size)
+		a.Equal(list.Len(), size)
+		a.NilError(Join(list, right))
+		assertSanity(a, list)
+		assertSanity(a, right)
+		a.Equal(list.Len(), N)
+		a.Equal(right.Len(), 0)
+
+		for i, elem := range elems {
+			a.Assert(elem.list == list)
+			a.Equal(elem.Index(), i)
+		}
+	}
+
+	// Join lists with different levels.
+	lhs := New(Int)
+	rhs := New(Int)
+	rhs.SetMaxLevel(DefaultMaxLevel * 2)
+
+	for i := 0; i < N; i++ {
+		lhs.Set(rand.Intn(N), i)
+		rhs.Set(N+rand.Intn(N), i)
+	}
+
+	total := lhs.Len() + rhs.Len()
+	a.NilError(Join(lhs, rhs))
+	assertSanity(a, lhs)
+	a.Equal(lhs.Len(), total)
+	lhs.Set(N, "middle")
+	assertSanity(a, lhs)
+}
+
+func TestJoinInvalidLists(t *testing.T) {
+	a := assert.New(t)
+	lhs := New(Int)
+	rhs := New(Int)
+
+	for i := 0; i < 10; i++ {
+		lhs.Set(i, i)
+		rhs.Set(i+9, i)
+	}
+
+	a.Equal(Join(lhs, lhs), ErrJoinSelf)
+	a.Equal(Join(lhs, New(Uint)), ErrDifferentComparable)
+	a.Assert(errors.Is(Join(lhs, rhs), ErrNotSorted))
+	a.Equal(lhs.Len(), 10)
+	a.Equal(rhs.Len(), 10)
+
+	rhs.Remove(9)
+	a.NilError(Join(lhs, rhs))
+	a.Equal(lhs.Len(), 19)
+	assertSanity(a, lhs)
+
+	dup := NewWithOptions(Int, Options{AllowDuplicates: true})
+	dup.Add(1, "a")
+	another := NewWithOptions(Int, Options{AllowDuplicates: true})
+	another.Add(1, "b")
+	a.NilError(Join(dup, another))
+	assertSanity(a, dup)
+	a.Equal(dup.Count(1), 2)
+
+	unique := New(Int)
+	unique.Set(1, "a")
+	another.Add(5, "x")
+	another.Add(5, "y")
+	a.Equal(Join(unique, another), ErrDuplicatesNotAllowed)
+	a.Equal(unique.Len(), 1)
+	a.Equal(another.Len(), 2)
+}
@@ -0,0 +1,114 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+// Stats is the statistics of a list returned by SkipList.Stats.
+type Stats struct {
+	LevelStats
+
+	Len          int // Count of elements.
+	PointerSlots int // Count of next pointers in all elements and the list header.
+
+	// Search costs of Get for every key in the list.
+	// The path length is the count of elements visited to reach the element.
+	AvgSearchPathLength float64
+	MaxSearchPathLength int
+	AvgComparisons      float64
+	MaxComparisons      int
+
+	// Counters are only available if the list is created with Options.CountCompares.
+	Compares     uint64 // Count of comparing a key with an element.
+	Fallthroughs uint64 // Count of comparing keys by comparable as scores are equal.
+}
+
+// Stats returns the statistics of levels and search costs of the list.
+// Search costs are calculated by walking through the list the same way as Get
+// without calling the comparable.
+//
+// The complexity is O(N*log(N)).
+func (list *SkipList) Stats() (stats Stats) {
+	stats.LevelStats = list.levelStats()
+	stats.Len = list.length
+	stats.PointerSlots = stats.Pointers + len(list.levels)
+	stats.Compares = list.compares.Load()
+	stats.Fallthroughs = list.fallthroughs.Load()
+
+	if list.length == 0 {
+		return
+	}
+
+	totalPathLength := 0
+	totalComparisons := 0
+
+	for rank := 1; rank <= list.length; rank++ {
+		pathLength, comparisons := list.searchCost(rank)
+		totalPathLength += pathLength
+		totalComparisons += comparisons
+		stats.MaxSearchPathLength = max(stats.MaxSearchPathLength, pathLength)
+		stats.MaxComparisons = max(stats.MaxComparisons, comparisons)
+	}
+
+	stats.AvgSearchPathLength = float64(totalPathLength) / float64(list.length)
+	stats.AvgComparisons = float64(totalComparisons) / float64(list.length)
+	return
+}
+
+// ResetCounters resets counters reported by Stats.
+func (list *SkipList) ResetCounters() {
+	list.compares.Store(0)
+	list.fallthroughs.Store(0)
+}
+
+// searchCost returns the path length and comparisons of Get to find the element at 1-based rank.
+// It follows the same steps as findNext but compares ranks instead of keys.
+func (list *SkipList) searchCost(rank int) (pathLength, comparisons int) {
+	// The findNext compares key with Front and Back first.
+	comparisons = 1
+	pathLength = 1
+
+	if rank == 1 {
+		return
+	}
+
+	comparisons++
+
+	prevHeader := &list.elementHeader
+	prevRank := 0
+	i := len(prevHeader.levels) - 1
+
+	for i >= 0 {
+		for next := prevHeader.levels[i]; next != nil; next = prevHeader.levels[i] {
+			comparisons++
+			nextRank := prevRank + prevHeader.spans[i]
+
+			if nextRank == rank && !list.duplicates {
+				return
+			}
+
+			if nextRank >= rank {
+				break
+			}
+
+			pathLength++
+			prevRank = nextRank
+			prevHeader = &next.elementHeader
+		}
+
+		topLevel := prevHeader.levels[i]
+
+		// Skip levels if they point to the same element as topLevel.
+		for i--; i >= 0 && prevHeader.levels[i] == topLevel; i-- {
+		}
+	}
+
+	return
+}
+
+func (list *SkipList) countCompare(score float64, rhs *Element) {
+	list.compares.Add(1)
+
+	if score == rhs.score {
+		list.fallthroughs.Add(1)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestStats(t *testing.T) {
+	a := assert.New(t)
+	list := NewWithOptions(Int, Options{CountCompares: true})
+
+	stats := list.Stats()
+	a.Equal(stats.Len, 0)
+	a.Equal(stats.PointerSlots, DefaultMaxLevel)
+	a.Equal(stats.MaxComparisons, 0)
+
+	const N = 10000
+
+	for i := 0; i < N; i++ {
+		list.Set(rand.Intn(N*10), i)
+	}
+
+	stats = list.Stats()
+	a.Equal(stats.Len, list.Len())
+	a.Equal(stats.LevelStats, list.levelStats())
+	a.Equal(stats.PointerSlots, stats.Pointers+DefaultMaxLevel)
+	a.Assert(stats.Compares > 0)
+	a.Assert(stats.MaxSearchPathLength >= int(stats.AvgSearchPathLength))
+	a.Assert(stats.MaxComparisons >= int(stats.AvgComparisons))
+
+	// Every Get compares keys one more time after searching.
+	// As scores of Int are exact, comparable is only called for equal keys,
+	// which happens twice for every key and once more for the back when comparing with Back().
+	list.ResetCounters()
+	a.Equal(list.Stats().Compares, uint64(0))
+
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		list.Get(elem.Key())
+	}
+
+	stats = list.Stats()
+	n := uint64(stats.Len)
+	a.Equal(stats.Compares, uint64(stats.AvgComparisons*float64(n)+0.5)+n)
+	a.Equal(stats.Fallthroughs, 2*n+1)
+
+	// Search cost of a rebalanced list is close to log2(N).
+	list.Rebalance()
+	stats = list.Stats()
+	a.Use(&stats)
+	a.Assert(stats.MaxSearchPathLength <= 15)
+}
+
+func TestStatsWithoutCounters(t *testing.T) {
+	a := assert.New(t)
+	list := New(String)
+
+	for i := 0; i < 100; i++ {
+		list.Set(string(rune('a'+i%26))+"key", i)
+	}
+
+	stats := list.Stats()
+	a.Equal(stats.Compares, uint64(0))
+	a.Equal(stats.Fallthroughs, uint64(0))
+	a.Equal(stats.Len, 26)
+	a.Assert(stats.AvgComparisons > 0)
+}
@@ -5,7 +5,9 @@ package skiplist
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"reflect"
 )
 
@@ -105,7 +107,10 @@ const (
 
 type keyType int
 
-var _ Comparable = keyType(0)
+var (
+	_ Comparable = keyType(0)
+	_ Codec      = keyType(0)
+)
 
 func (kt keyType) kind() (kind reflect.Kind, reversed bool) {
 	if kt < 0 {
@@ -306,3 +311,96 @@ func calcScore(val reflect.Value) (score float64) {
 
 	return
 }
+
+var keyTypes = [...]reflect.Type{
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Uintptr: reflect.TypeOf(uintptr(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Slice:   typeOfBytes,
+}
+
+// Marshal encodes a key in binary.
+// Integers are encoded as varints, floats are encoded as IEEE 754 bits
+// and strings and bytes are encoded as is.
+func (kt keyType) Marshal(key interface{}) (data []byte, err error) {
+	kind, _ := kt.kind()
+	val := reflect.ValueOf(key)
+
+	if !val.IsValid() || !val.Type().ConvertibleTo(keyTypes[kind]) {
+		err = fmt.Errorf("skiplist: cannot marshal key `%v` as %v", key, keyTypes[kind])
+		return
+	}
+
+	val = val.Convert(keyTypes[kind])
+
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		data = binary.AppendVarint(nil, val.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		data = binary.AppendUvarint(nil, val.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		data = binary.BigEndian.AppendUint64(nil, math.Float64bits(val.Float()))
+
+	case reflect.String:
+		data = []byte(val.String())
+
+	case reflect.Slice:
+		data = append([]byte{}, val.Bytes()...)
+	}
+
+	return
+}
+
+// Unmarshal decodes a key encoded by Marshal.
+// The type of key is the Go type of kt, e.g. int for Int, []byte for Bytes.
+func (kt keyType) Unmarshal(data []byte) (key interface{}, err error) {
+	kind, _ := kt.kind()
+	val := reflect.New(keyTypes[kind]).Elem()
+	valid := true
+
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, n := binary.Varint(data)
+		valid = n > 0 && n == len(data) && !val.OverflowInt(v)
+		val.SetInt(v)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v, n := binary.Uvarint(data)
+		valid = n > 0 && n == len(data) && !val.OverflowUint(v)
+		val.SetUint(v)
+
+	case reflect.Float32, reflect.Float64:
+		valid = len(data) == 8
+
+		if valid {
+			val.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(data)))
+		}
+
+	case reflect.String:
+		val.SetString(string(data))
+
+	case reflect.Slice:
+		val.SetBytes(append([]byte{}, data...))
+	}
+
+	if !valid {
+		err = fmt.Errorf("%w: invalid %v key", ErrCorruptedData, keyTypes[kind])
+		return
+	}
+
+	key = val.Interface()
+	return
+}
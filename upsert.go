@@ -0,0 +1,105 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+// GetOrSet returns the element with the key if it exists and loaded is true.
+// Otherwise, sets value for the key and returns the new element.
+// If duplicates are allowed and there are elements with the key, returns the first one.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) GetOrSet(key, value interface{}) (elem *Element, loaded bool) {
+	score := list.calcScore(key)
+
+	var path searchPath
+	prevElemHeaders, prevRanks := path.alloc(len(list.levels))
+
+	if elem = list.search(score, key, false, prevElemHeaders, prevRanks); elem != nil {
+		loaded = true
+		return
+	}
+
+	elem = list.link(score, key, value, prevElemHeaders, prevRanks)
+	return
+}
+
+// Compute calls fn with current value of the key and decides what to do with the key by the result of fn.
+// If the key doesn't exist, fn is called with a nil old and false exists.
+//
+//   - If keep is true, the value is set to the key. A new element is added if the key doesn't exist.
+//   - If keep is false, the element with the key is removed if any.
+//
+// Returns the element holding the key after compute.
+// If the key doesn't exist after compute, returns nil.
+// If duplicates are allowed and there are elements with the key, only the first one is computed.
+//
+// The fn must not change the list.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Compute(key interface{}, fn func(old interface{}, exists bool) (value interface{}, keep bool)) (elem *Element) {
+	score := list.calcScore(key)
+
+	var path searchPath
+	prevElemHeaders, prevRanks := path.alloc(len(list.levels))
+
+	if elem = list.search(score, key, false, prevElemHeaders, prevRanks); elem != nil {
+		value, keep := fn(elem.Value, true)
+
+		if !keep {
+			list.RemoveElement(elem)
+			elem = nil
+			return
+		}
+
+		elem.Value = value
+		list.UpdateAggregates(elem)
+		return
+	}
+
+	value, keep := fn(nil, false)
+
+	if !keep {
+		return
+	}
+
+	elem = list.link(score, key, value, prevElemHeaders, prevRanks)
+	return
+}
+
+// Update sets the value of the key to the result of fn if the key exists.
+// Returns the updated element or nil if the key doesn't exist.
+// If duplicates are allowed and there are elements with the key, only the first one is updated.
+//
+// The fn must not change the list.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) Update(key interface{}, fn func(old interface{}) interface{}) (elem *Element) {
+	elem = list.Get(key)
+
+	if elem == nil {
+		return
+	}
+
+	elem.Value = fn(elem.Value)
+	list.UpdateAggregates(elem)
+	return
+}
+
+// CompareAndSwap sets the value of the key to new if current value is equal to old.
+// Values are compared by ==. The old must be a comparable value.
+// Returns true if the value is swapped.
+// If duplicates are allowed and there are elements with the key, only the first one is compared.
+//
+// The complexity is O(log(N)).
+func (list *SkipList) CompareAndSwap(key, old, new interface{}) (swapped bool) {
+	elem := list.Get(key)
+
+	if elem == nil || elem.Value != old {
+		return
+	}
+
+	elem.Value = new
+	list.UpdateAggregates(elem)
+	swapped = true
+	return
+}
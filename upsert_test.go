@@ -0,0 +1,156 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestGetOrSet(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+
+	elem, loaded := list.GetOrSet(10, "ten")
+	a.Assert(!loaded)
+	a.Equal(elem.Value, "ten")
+
+	for i := 0; i < 100; i++ {
+		list.Set(i*3, i)
+	}
+
+	actual, loaded := list.GetOrSet(10, "another ten")
+	a.Assert(loaded)
+	a.Equal(actual, elem)
+	a.Equal(elem.Value, "ten")
+
+	elem, loaded = list.GetOrSet(100, "hundred")
+	a.Assert(!loaded)
+	a.Equal(list.MustGetValue(100), "hundred")
+	a.Equal(elem.Index(), list.IndexOf(100))
+	assertSanity(a, list)
+
+	dup := NewWithOptions(Int, Options{AllowDuplicates: true})
+	first := dup.Add(1, "a")
+	dup.Add(1, "b")
+	elem, loaded = dup.GetOrSet(1, "c")
+	a.Assert(loaded)
+	a.Equal(elem, first)
+	a.Equal(dup.Len(), 2)
+}
+
+func TestCompute(t *testing.T) {
+	a := assert.New(t)
+	list := New(Int)
+	incr := func(old interface{}, exists bool) (interface{}, bool) {
+		if !exists {
+			return 1, true
+		}
+
+		return old.(int) + 1, true
+	}
+
+	for i := 0; i < 100; i++ {
+		list.Compute(i%10, incr)
+	}
+
+	a.Equal(list.Len(), 10)
+	assertSanity(a, list)
+
+	for i := 0; i < 10; i++ {
+		a.Equal(list.MustGetValue(i), 10)
+	}
+
+	elem := list.Compute(5, func(old interface{}, exists bool) (interface{}, bool) {
+		a.Assert(exists)
+		a.Equal(old, 10)
+		return nil, false
+	})
+	a.Assert(elem == nil)
+	a.Assert(list.Get(5) == nil)
+
+	elem = list.Compute(50, func(old interface{}, exists bool) (interface{}, bool) {
+		a.Assert(!exists)
+		a.Equal(old, nil)
+		return nil, false
+	})
+	a.Assert(elem == nil)
+	a.Equal(list.Len(), 9)
+	assertSanity(a, list)
+}
+
+func TestUpdateAndCompareAndSwap(t *testing.T) {
+	a := assert.New(t)
+	list := New(String)
+	list.Set("a", 1)
+	list.Set("b", 2)
+
+	elem := list.Update("a", func(old interface{}) interface{} {
+		return old.(int) * 10
+	})
+	a.Equal(elem.Key(), "a")
+	a.Equal(list.MustGetValue("a"), 10)
+	a.Assert(list.Update("c", func(old interface{}) interface{} {
+		t.Fatalf("fn must not be called for missing key")
+		return nil
+	}) == nil)
+	a.Equal(list.Len(), 2)
+
+	a.Assert(!list.CompareAndSwap("b", 1, 3))
+	a.Equal(list.MustGetValue("b"), 2)
+	a.Assert(list.CompareAndSwap("b", 2, 3))
+	a.Equal(list.MustGetValue("b"), 3)
+	a.Assert(!list.CompareAndSwap("c", nil, 3))
+	a.Equal(list.Len(), 2)
+}
+
+func TestConcurrentCompute(t *testing.T) {
+	a := assert.New(t)
+	cl := NewConcurrent(Int)
+
+	const workers = 8
+	const N = 1000
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < N; i++ {
+				cl.Compute(i%10, func(old interface{}, exists bool) (interface{}, bool) {
+					if !exists {
+						return 1, true
+					}
+
+					return old.(int) + 1, true
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		a.Equal(cl.MustGetValue(i), workers*N/10)
+	}
+
+	_, loaded := cl.GetOrSet(0, 0)
+	a.Assert(loaded)
+	a.Assert(cl.CompareAndSwap(0, workers*N/10, 0))
+	a.Equal(cl.MustGetValue(0), 0)
+
+	elem := cl.Update(1, func(old interface{}) interface{} {
+		return old.(int) * 2
+	})
+	a.Equal(elem.Key(), 1)
+	a.Equal(cl.MustGetValue(1), workers*N/10*2)
+	a.Assert(cl.Update(10, func(old interface{}) interface{} {
+		t.Fatalf("fn must not be called for missing key")
+		return nil
+	}) == nil)
+}
@@ -0,0 +1,152 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"fmt"
+	"math"
+)
+
+// Validate walks through all elements and levels to check whether the list is well-formed.
+// Returns an error describing the first bad element if any.
+//
+// Following rules are checked.
+//
+//   - Elements are sorted by score and then by comparable.
+//     Scores must be consistent with comparable as required by Scorable.
+//   - Every element's score is equal to CalcScore of its key.
+//   - Every element points to the list.
+//   - Prev, PrevLevel and NextLevel of every element are consistent on all levels.
+//   - Spans of levels, which are used by Index and GetByIndex, are correct.
+//   - Back and Len are correct.
+//
+// It's useful to find out bugs in a custom comparable.
+//
+// The complexity is O(N).
+func (list *SkipList) Validate() error {
+	max := len(list.levels)
+	tails := make([]*elementHeader, max) // Last element visited on every level.
+	tailIndexes := make([]int, max)      // Index of tails. List header's index is -1.
+
+	for i := range tails {
+		tails[i] = &list.elementHeader
+		tailIndexes[i] = -1
+	}
+
+	var prev *Element
+	index := 0
+
+	for elem := list.Front(); elem != nil; elem = elem.Next() {
+		if index >= list.length {
+			return fmt.Errorf("skiplist: there are more elements than Len() %v", list.length)
+		}
+
+		if err := list.validateElement(elem, prev); err != nil {
+			return fmt.Errorf("skiplist: element at index %v with key `%v` is invalid: %w", index, elem.key, err)
+		}
+
+		level := elem.Level()
+
+		if level > max {
+			return fmt.Errorf("skiplist: element at index %v with key `%v` is invalid: level %v is larger than list level %v", index, elem.key, level, max)
+		}
+
+		// Elements on level i must be all elements with level larger than i.
+		for i := 0; i < level; i++ {
+			tail := tails[i]
+
+			if tail.levels[i] != elem {
+				return fmt.Errorf("skiplist: element at index %v with key `%v` is invalid: it's not linked on level %v", index, elem.key, i)
+			}
+
+			if span := index - tailIndexes[i]; tail.spans[i] != span {
+				return fmt.Errorf("skiplist: element at index %v with key `%v` is invalid: span on level %v is %v but %v is expected", index, elem.key, i, tail.spans[i], span)
+			}
+		}
+
+		var prevTopLevel *Element
+
+		if tail := tails[level-1]; tail != &list.elementHeader {
+			prevTopLevel = tail.Element()
+		}
+
+		if elem.prevTopLevel != prevTopLevel {
+			return fmt.Errorf("skiplist: element at index %v with key `%v` is invalid: previous element on top level is wrong", index, elem.key)
+		}
+
+		for i := 0; i < level; i++ {
+			tails[i] = &elem.elementHeader
+			tailIndexes[i] = index
+		}
+
+		prev = elem
+		index++
+	}
+
+	if index != list.length {
+		return fmt.Errorf("skiplist: there are %v elements but Len() is %v", index, list.length)
+	}
+
+	if list.back != prev {
+		return fmt.Errorf("skiplist: Back() is not the last element")
+	}
+
+	for i, tail := range tails {
+		if tail.levels[i] != nil {
+			return fmt.Errorf("skiplist: last element on level %v points to another element", i)
+		}
+	}
+
+	// The list header points to an element on every level in use.
+	level := 0
+
+	for level < max && list.levels[level] != nil {
+		level++
+	}
+
+	if list.level != level {
+		return fmt.Errorf("skiplist: %v levels are in use but list level is %v", level, list.level)
+	}
+
+	return nil
+}
+
+// validateElement checks elem itself and its order with the prev element.
+func (list *SkipList) validateElement(elem, prev *Element) error {
+	if elem.list != list {
+		return fmt.Errorf("it doesn't point to the list")
+	}
+
+	if elem.Level() == 0 {
+		return fmt.Errorf("it has no level")
+	}
+
+	if elem.prev != prev {
+		return fmt.Errorf("prev element is wrong")
+	}
+
+	if score := list.calcScore(elem.key); score != elem.score && !(math.IsNaN(score) && math.IsNaN(elem.score)) {
+		return fmt.Errorf("score %v is not equal to CalcScore(key) %v", elem.score, score)
+	}
+
+	if prev == nil {
+		return nil
+	}
+
+	if elem.score < prev.score {
+		return fmt.Errorf("score %v is less than previous score %v", elem.score, prev.score)
+	}
+
+	comp := list.comparable.Compare(elem.key, prev.key)
+
+	if comp < 0 || comp == 0 && !list.duplicates {
+		if elem.score > prev.score {
+			return fmt.Errorf("score %v is greater than previous score %v but Compare(key, `%v`) returns %v", elem.score, prev.score, prev.key, comp)
+		}
+
+		return fmt.Errorf("key is not greater than previous key `%v`", prev.key)
+	}
+
+	return nil
+}
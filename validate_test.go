@@ -0,0 +1,98 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+	a.NilError(New(Int).Validate())
+
+	newList := func() *SkipList {
+		list := New(Int)
+
+		for i := 0; i < 100; i++ {
+			list.Set(i, i)
+		}
+
+		a.NilError(list.Validate())
+		return list
+	}
+	assertError := func(list *SkipList, msg string) {
+		err := list.Validate()
+		a.Use(&msg)
+		a.Assert(err != nil && strings.Contains(err.Error(), msg))
+	}
+
+	list := newList()
+	list.GetByIndex(10).score = 100
+	assertError(list, "element at index 10 with key `10` is invalid: score 100 is not equal to CalcScore(key) 10")
+
+	list = newList()
+	elem := list.GetByIndex(20)
+	elem.key = 10
+	elem.score = 10
+	assertError(list, "element at index 20 with key `10` is invalid: score 10 is less than previous score 19")
+
+	list = newList()
+	elem = list.GetByIndex(20)
+	elem.key = 19
+	elem.score = 19
+	assertError(list, "element at index 20 with key `19` is invalid: key is not greater than previous key `19`")
+
+	list = newList()
+	list.GetByIndex(30).list = New(Int)
+	assertError(list, "element at index 30 with key `30` is invalid: it doesn't point to the list")
+
+	list = newList()
+	list.GetByIndex(40).prev = nil
+	assertError(list, "element at index 40 with key `40` is invalid: prev element is wrong")
+
+	list = newList()
+	list.spans[0]++
+	assertError(list, "element at index 0 with key `0` is invalid: span on level 0 is 2 but 1 is expected")
+
+	list = newList()
+	elem = list.GetByIndex(50)
+	elem.prevTopLevel = elem
+	assertError(list, "element at index 50 with key `50` is invalid: previous element on top level is wrong")
+
+	list = newList()
+	list.length++
+	assertError(list, "there are 100 elements but Len() is 101")
+
+	list = newList()
+	list.length--
+	assertError(list, "there are more elements than Len() 99")
+
+	list = newList()
+	list.back = list.Front()
+	assertError(list, "Back() is not the last element")
+}
+
+type inconsistentComparable struct{}
+
+func (inconsistentComparable) Compare(lhs, rhs interface{}) int {
+	return Int.Compare(rhs, lhs)
+}
+
+func (inconsistentComparable) CalcScore(key interface{}) float64 {
+	return Int.CalcScore(key)
+}
+
+func TestValidateInconsistentComparable(t *testing.T) {
+	a := assert.New(t)
+	list := New(inconsistentComparable{})
+	list.Set(1, "one")
+	list.Set(2, "two")
+
+	err := list.Validate()
+	a.Assert(err != nil)
+	a.Equal(err.Error(), "skiplist: element at index 1 with key `2` is invalid: score 2 is greater than previous score 1 but Compare(key, `1`) returns -1")
+}
@@ -0,0 +1,463 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Z is a member with its score in a ZSet.
+type Z struct {
+	Score  float64
+	Member string
+}
+
+// ZAddFlag is the flag to change behavior of ZSet.ZAdd.
+// Flags can be combined with bitwise OR.
+type ZAddFlag int
+
+// Flags for ZSet.ZAdd. They have the same meaning as flags of Redis ZADD command.
+const (
+	ZAddNX ZAddFlag = 1 << iota // Only add new members. Don't update existing members.
+	ZAddXX                      // Only update existing members. Don't add new members.
+	ZAddGT                      // Only update existing members if new score is greater than current score.
+	ZAddLT                      // Only update existing members if new score is less than current score.
+	ZAddCH                      // Return count of added and changed members instead of added members.
+)
+
+// Errors returned by ZSet. Messages are the same as Redis.
+var (
+	ErrZAddXXAndNX      = errors.New("ERR XX and NX options at the same time are not compatible")
+	ErrZAddGTLTAndNX    = errors.New("ERR GT, LT, and/or NX options at the same time are not compatible")
+	ErrZNotFloat        = errors.New("ERR value is not a valid float")
+	ErrZScoreNaN        = errors.New("ERR resulting score is not a number (NaN)")
+	ErrZInvalidLexRange = errors.New("ERR min or max not valid string range item")
+)
+
+// ZSet is a sorted set compatible with Redis sorted set.
+//
+// Members are unique strings and they are sorted by score first
+// and then by member in lexicographical order.
+// A hash from member to element is kept along with a skip list,
+// so that looking up a member's score is O(1).
+//
+// ZSet is not goroutine-safe.
+type ZSet struct {
+	dict map[string]*Element
+	list *SkipList
+}
+
+// zsetKey is the key of elements in ZSet's skip list.
+type zsetKey struct {
+	score  float64
+	member string
+
+	// Bound is used to seek in the list by score only.
+	// A negative bound is less than all members with the same score
+	// and a positive bound is greater than all members with the same score.
+	bound int
+}
+
+type zsetComparable struct{}
+
+var _ Comparable = zsetComparable{}
+
+func (zsetComparable) Compare(lhs, rhs interface{}) int {
+	k1 := lhs.(zsetKey)
+	k2 := rhs.(zsetKey)
+
+	if k1.score != k2.score {
+		if k1.score > k2.score {
+			return 1
+		}
+
+		return -1
+	}
+
+	if k1.bound != k2.bound {
+		if k1.bound > k2.bound {
+			return 1
+		}
+
+		return -1
+	}
+
+	return strings.Compare(k1.member, k2.member)
+}
+
+func (zsetComparable) CalcScore(key interface{}) float64 {
+	return key.(zsetKey).score
+}
+
+// NewZSet creates a new empty sorted set.
+func NewZSet() *ZSet {
+	return &ZSet{
+		dict: map[string]*Element{},
+		list: New(zsetComparable{}),
+	}
+}
+
+// ZCard returns the count of members.
+//
+// The complexity is O(1).
+func (zs *ZSet) ZCard() int {
+	return zs.list.Len()
+}
+
+// ZAdd adds members with scores or updates scores of existing members.
+// Flags change the behavior in the same way as Redis ZADD command.
+// Returns the count of added members.
+// If ZAddCH is set, returns the count of added and changed members.
+//
+// The complexity is O(M*log(N)), where M is the count of members to add.
+func (zs *ZSet) ZAdd(flags ZAddFlag, members ...Z) (n int, err error) {
+	nx := flags&ZAddNX != 0
+	xx := flags&ZAddXX != 0
+	gt := flags&ZAddGT != 0
+	lt := flags&ZAddLT != 0
+
+	if nx && xx {
+		err = ErrZAddXXAndNX
+		return
+	}
+
+	if gt && lt || (gt || lt) && nx {
+		err = ErrZAddGTLTAndNX
+		return
+	}
+
+	for _, z := range members {
+		if math.IsNaN(z.Score) {
+			err = ErrZNotFloat
+			return
+		}
+	}
+
+	added := 0
+	changed := 0
+
+	for _, z := range members {
+		elem, ok := zs.dict[z.Member]
+
+		if !ok {
+			if xx {
+				continue
+			}
+
+			zs.insert(z.Score, z.Member)
+			added++
+			continue
+		}
+
+		if nx {
+			continue
+		}
+
+		score := elem.key.(zsetKey).score
+
+		if gt && z.Score <= score || lt && z.Score >= score || z.Score == score {
+			continue
+		}
+
+		zs.update(elem, z.Score)
+		changed++
+	}
+
+	n = added
+
+	if flags&ZAddCH != 0 {
+		n += changed
+	}
+
+	return
+}
+
+// ZIncrBy increments the score of member by increment and returns the new score.
+// If member doesn't exist, it's added with increment as score.
+//
+// The complexity is O(log(N)).
+func (zs *ZSet) ZIncrBy(increment float64, member string) (score float64, err error) {
+	if math.IsNaN(increment) {
+		err = ErrZNotFloat
+		return
+	}
+
+	elem, ok := zs.dict[member]
+
+	if !ok {
+		score = increment
+		zs.insert(score, member)
+		return
+	}
+
+	score = elem.key.(zsetKey).score + increment
+
+	if math.IsNaN(score) {
+		err = ErrZScoreNaN
+		return
+	}
+
+	zs.update(elem, score)
+	return
+}
+
+// ZRem removes members and returns the count of removed members.
+//
+// The complexity is O(M*log(N)), where M is the count of members to remove.
+func (zs *ZSet) ZRem(members ...string) (n int) {
+	for _, member := range members {
+		elem, ok := zs.dict[member]
+
+		if !ok {
+			continue
+		}
+
+		delete(zs.dict, member)
+		zs.list.RemoveElement(elem)
+		n++
+	}
+
+	return
+}
+
+// ZScore returns the score of member.
+// If member doesn't exist, ok is false.
+//
+// The complexity is O(1).
+func (zs *ZSet) ZScore(member string) (score float64, ok bool) {
+	elem, ok := zs.dict[member]
+
+	if !ok {
+		return
+	}
+
+	score = elem.key.(zsetKey).score
+	return
+}
+
+// ZRank returns the 0-based rank of member ordered from the lowest score to the highest score.
+// If member doesn't exist, ok is false.
+//
+// The complexity is O(log(N)).
+func (zs *ZSet) ZRank(member string) (rank int, ok bool) {
+	elem, ok := zs.dict[member]
+
+	if !ok {
+		return
+	}
+
+	rank = elem.Index()
+	return
+}
+
+// ZRevRank returns the 0-based rank of member ordered from the highest score to the lowest score.
+// If member doesn't exist, ok is false.
+//
+// The complexity is O(log(N)).
+func (zs *ZSet) ZRevRank(member string) (rank int, ok bool) {
+	rank, ok = zs.ZRank(member)
+
+	if !ok {
+		return
+	}
+
+	rank = zs.list.Len() - 1 - rank
+	return
+}
+
+// ZRangeByScore returns members with scores between min and max ordered from the lowest score.
+// If excludeMin or excludeMax is true, the score equal to min or max is excluded
+// like "(min" or "(max" in Redis ZRANGEBYSCORE command.
+// Use math.Inf(-1) and math.Inf(1) for "-inf" and "+inf".
+//
+// The offset and count work like LIMIT in Redis.
+// If offset is negative, returns nothing.
+// If count is negative, returns all members from offset.
+//
+// The complexity is O(log(N)+M), where M is the count of returned members.
+func (zs *ZSet) ZRangeByScore(min, max float64, excludeMin, excludeMax bool, offset, count int) (members []Z) {
+	if offset < 0 || count == 0 {
+		return
+	}
+
+	from := zsetKey{score: min, bound: -1}
+	to := zsetKey{score: max, bound: 1}
+
+	if excludeMin {
+		from.bound = 1
+	}
+
+	if excludeMax {
+		to.bound = -1
+	}
+
+	it := zs.list.Range(from, to, &RangeOptions{
+		Offset: offset,
+		Limit:  count,
+	})
+
+	for elem := it.Next(); elem != nil; elem = it.Next() {
+		members = append(members, zs.z(elem))
+	}
+
+	return
+}
+
+// ZRangeByLex returns members between min and max in lexicographical order.
+// The min and max are in the same format as Redis ZRANGEBYLEX command.
+// They must start with "[" for inclusive or "(" for exclusive, or be "-" or "+" for infinities.
+//
+// As in Redis, all members must have the same score.
+// Otherwise, the result is unspecified.
+//
+// The offset and count work like LIMIT in Redis.
+// If offset is negative, returns nothing.
+// If count is negative, returns all members from offset.
+//
+// The complexity is O(log(N)+M), where M is the count of returned members.
+func (zs *ZSet) ZRangeByLex(min, max string, offset, count int) (members []string, err error) {
+	minRange, err := parseZLexBound(min)
+
+	if err != nil {
+		return
+	}
+
+	maxRange, err := parseZLexBound(max)
+
+	if err != nil {
+		return
+	}
+
+	if offset < 0 || count == 0 {
+		return
+	}
+
+	// Find the first member in range by comparing members only.
+	list := zs.list
+	prevHeader := &list.elementHeader
+
+	for i := len(prevHeader.levels) - 1; i >= 0; i-- {
+		for next := prevHeader.levels[i]; next != nil && !minRange.lessOrEqual(next.key.(zsetKey).member); next = prevHeader.levels[i] {
+			prevHeader = &next.elementHeader
+		}
+	}
+
+	elem := prevHeader.levels[0]
+
+	if elem != nil && offset > 0 {
+		elem = list.GetByIndex(elem.Index() + offset)
+	}
+
+	for ; elem != nil && count != 0; elem = elem.Next() {
+		member := elem.key.(zsetKey).member
+
+		if !maxRange.greaterOrEqual(member) {
+			break
+		}
+
+		members = append(members, member)
+		count--
+	}
+
+	return
+}
+
+// ZPopMin removes and returns up to count members with the lowest scores.
+//
+// The complexity is O(M*log(N)), where M is the count of removed members.
+func (zs *ZSet) ZPopMin(count int) (members []Z) {
+	for ; count > 0 && zs.list.Len() > 0; count-- {
+		elem := zs.list.RemoveFront()
+		z := zs.z(elem)
+		delete(zs.dict, z.Member)
+		members = append(members, z)
+	}
+
+	return
+}
+
+// ZPopMax removes and returns up to count members with the highest scores.
+//
+// The complexity is O(M*log(N)), where M is the count of removed members.
+func (zs *ZSet) ZPopMax(count int) (members []Z) {
+	for ; count > 0 && zs.list.Len() > 0; count-- {
+		elem := zs.list.RemoveBack()
+		z := zs.z(elem)
+		delete(zs.dict, z.Member)
+		members = append(members, z)
+	}
+
+	return
+}
+
+func (zs *ZSet) insert(score float64, member string) {
+	zs.dict[member] = zs.list.Set(zsetKey{score: score, member: member}, nil)
+}
+
+func (zs *ZSet) update(elem *Element, score float64) {
+	key := elem.key.(zsetKey)
+	key.score = score
+	zs.list.Rekey(elem, key)
+}
+
+func (zs *ZSet) z(elem *Element) Z {
+	key := elem.key.(zsetKey)
+	return Z{
+		Score:  key.score,
+		Member: key.member,
+	}
+}
+
+// zlexBound is a parsed bound of ZRangeByLex.
+type zlexBound struct {
+	member    string
+	exclusive bool
+	inf       int // -1 for "-", 1 for "+" and 0 for others.
+}
+
+func parseZLexBound(s string) (bound zlexBound, err error) {
+	switch {
+	case s == "-":
+		bound.inf = -1
+	case s == "+":
+		bound.inf = 1
+	case strings.HasPrefix(s, "["):
+		bound.member = s[1:]
+	case strings.HasPrefix(s, "("):
+		bound.member = s[1:]
+		bound.exclusive = true
+	default:
+		err = ErrZInvalidLexRange
+	}
+
+	return
+}
+
+// lessOrEqual returns true if member is in the range starting from bound.
+func (bound zlexBound) lessOrEqual(member string) bool {
+	if bound.inf != 0 {
+		return bound.inf < 0
+	}
+
+	if bound.exclusive {
+		return bound.member < member
+	}
+
+	return bound.member <= member
+}
+
+// greaterOrEqual returns true if member is in the range ending at bound.
+func (bound zlexBound) greaterOrEqual(member string) bool {
+	if bound.inf != 0 {
+		return bound.inf > 0
+	}
+
+	if bound.exclusive {
+		return member < bound.member
+	}
+
+	return member <= bound.member
+}
@@ -0,0 +1,186 @@
+// Copyright 2011 Huan Du. All rights reserved.
+// Licensed under the MIT license that can be found in the LICENSE file.
+
+package skiplist
+
+import (
+	"math"
+	"testing"
+
+	"github.com/huandu/go-assert"
+)
+
+func TestZSetZAdd(t *testing.T) {
+	a := assert.New(t)
+	zs := NewZSet()
+
+	n, err := zs.ZAdd(0, Z{1, "one"}, Z{2, "two"}, Z{3, "three"})
+	a.NilError(err)
+	a.Equal(n, 3)
+	a.Equal(zs.ZCard(), 3)
+
+	// Update existing members.
+	n, err = zs.ZAdd(0, Z{10, "one"}, Z{4, "four"})
+	a.NilError(err)
+	a.Equal(n, 1)
+	n, err = zs.ZAdd(ZAddCH, Z{11, "one"}, Z{2, "two"}, Z{5, "five"})
+	a.NilError(err)
+	a.Equal(n, 2)
+
+	score, ok := zs.ZScore("one")
+	a.Assert(ok)
+	a.Equal(score, 11.0)
+
+	// NX only adds new members.
+	n, err = zs.ZAdd(ZAddNX|ZAddCH, Z{100, "one"}, Z{6, "six"})
+	a.NilError(err)
+	a.Equal(n, 1)
+	score, _ = zs.ZScore("one")
+	a.Equal(score, 11.0)
+
+	// XX only updates existing members.
+	n, err = zs.ZAdd(ZAddXX|ZAddCH, Z{1, "one"}, Z{7, "seven"})
+	a.NilError(err)
+	a.Equal(n, 1)
+	_, ok = zs.ZScore("seven")
+	a.Assert(!ok)
+
+	// GT and LT only update existing members in one direction but always add new members.
+	n, err = zs.ZAdd(ZAddGT|ZAddCH, Z{0, "one"}, Z{3, "two"}, Z{8, "eight"})
+	a.NilError(err)
+	a.Equal(n, 2)
+	score, _ = zs.ZScore("one")
+	a.Equal(score, 1.0)
+	score, _ = zs.ZScore("two")
+	a.Equal(score, 3.0)
+
+	n, err = zs.ZAdd(ZAddLT|ZAddCH, Z{0, "one"}, Z{4, "two"})
+	a.NilError(err)
+	a.Equal(n, 1)
+	score, _ = zs.ZScore("one")
+	a.Equal(score, 0.0)
+
+	// Invalid flags and scores.
+	_, err = zs.ZAdd(ZAddNX|ZAddXX, Z{1, "x"})
+	a.Equal(err, ErrZAddXXAndNX)
+	_, err = zs.ZAdd(ZAddNX|ZAddGT, Z{1, "x"})
+	a.Equal(err, ErrZAddGTLTAndNX)
+	_, err = zs.ZAdd(ZAddGT|ZAddLT, Z{1, "x"})
+	a.Equal(err, ErrZAddGTLTAndNX)
+	_, err = zs.ZAdd(0, Z{1, "x"}, Z{math.NaN(), "y"})
+	a.Equal(err, ErrZNotFloat)
+	_, ok = zs.ZScore("x")
+	a.Assert(!ok)
+
+	assertSanity(a, zs.list)
+	a.Equal(len(zs.dict), zs.ZCard())
+}
+
+func TestZSetZIncrBy(t *testing.T) {
+	a := assert.New(t)
+	zs := NewZSet()
+
+	score, err := zs.ZIncrBy(2.5, "a")
+	a.NilError(err)
+	a.Equal(score, 2.5)
+	score, err = zs.ZIncrBy(-1, "a")
+	a.NilError(err)
+	a.Equal(score, 1.5)
+
+	zs.ZIncrBy(math.Inf(1), "inf")
+	_, err = zs.ZIncrBy(math.Inf(-1), "inf")
+	a.Equal(err, ErrZScoreNaN)
+	score, _ = zs.ZScore("inf")
+	a.Equal(score, math.Inf(1))
+
+	_, err = zs.ZIncrBy(math.NaN(), "a")
+	a.Equal(err, ErrZNotFloat)
+}
+
+func TestZSetRankAndRange(t *testing.T) {
+	a := assert.New(t)
+	zs := NewZSet()
+	zs.ZAdd(0, Z{1, "a"}, Z{2, "b"}, Z{2, "c"}, Z{3, "d"}, Z{math.Inf(-1), "min"}, Z{math.Inf(1), "max"})
+
+	rank, ok := zs.ZRank("b")
+	a.Assert(ok)
+	a.Equal(rank, 2)
+	rank, _ = zs.ZRank("min")
+	a.Equal(rank, 0)
+	rank, _ = zs.ZRevRank("max")
+	a.Equal(rank, 0)
+	rank, _ = zs.ZRevRank("a")
+	a.Equal(rank, 4)
+	_, ok = zs.ZRank("none")
+	a.Assert(!ok)
+	_, ok = zs.ZRevRank("none")
+	a.Assert(!ok)
+
+	a.Equal(zs.ZRangeByScore(1, 2, false, false, 0, -1), []Z{{1, "a"}, {2, "b"}, {2, "c"}})
+	a.Equal(zs.ZRangeByScore(1, 2, true, false, 0, -1), []Z{{2, "b"}, {2, "c"}})
+	a.Equal(zs.ZRangeByScore(1, 2, false, true, 0, -1), []Z{{1, "a"}})
+	a.Equal(zs.ZRangeByScore(2, 2, false, false, 1, 1), []Z{{2, "c"}})
+	a.Equal(zs.ZRangeByScore(math.Inf(-1), math.Inf(1), false, false, 0, 2), []Z{{math.Inf(-1), "min"}, {1, "a"}})
+	a.Equal(zs.ZRangeByScore(math.Inf(-1), math.Inf(1), true, true, 0, -1), []Z{{1, "a"}, {2, "b"}, {2, "c"}, {3, "d"}})
+	a.Equal(zs.ZRangeByScore(3, 1, false, false, 0, -1), nil)
+	a.Equal(zs.ZRangeByScore(1, 3, false, false, -1, -1), nil)
+	a.Equal(zs.ZRangeByScore(1, 3, false, false, 0, 0), nil)
+	a.Equal(zs.ZRangeByScore(1, 3, false, false, 10, -1), nil)
+
+	a.Equal(zs.ZRem("a", "b", "none"), 2)
+	a.Equal(zs.ZCard(), 4)
+	assertSanity(a, zs.list)
+}
+
+func TestZSetZRangeByLex(t *testing.T) {
+	a := assert.New(t)
+	zs := NewZSet()
+
+	for _, m := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		zs.ZAdd(0, Z{0, m})
+	}
+
+	cases := []struct {
+		min, max      string
+		offset, count int
+		members       []string
+	}{
+		{"-", "[c", 0, -1, []string{"a", "b", "c"}},
+		{"-", "(c", 0, -1, []string{"a", "b"}},
+		{"[aaa", "(g", 0, -1, []string{"b", "c", "d", "e", "f"}},
+		{"(b", "+", 0, -1, []string{"c", "d", "e", "f", "g"}},
+		{"-", "+", 2, 3, []string{"c", "d", "e"}},
+		{"+", "-", 0, -1, nil},
+		{"[c", "[c", 0, -1, []string{"c"}},
+		{"(c", "(c", 0, -1, nil},
+		{"-", "+", -1, -1, nil},
+	}
+
+	for i, c := range cases {
+		a.Use(&i, &c)
+		members, err := zs.ZRangeByLex(c.min, c.max, c.offset, c.count)
+		a.NilError(err)
+		a.Equal(members, c.members)
+	}
+
+	_, err := zs.ZRangeByLex("a", "+", 0, -1)
+	a.Equal(err, ErrZInvalidLexRange)
+	_, err = zs.ZRangeByLex("-", "", 0, -1)
+	a.Equal(err, ErrZInvalidLexRange)
+}
+
+func TestZSetZPop(t *testing.T) {
+	a := assert.New(t)
+	zs := NewZSet()
+	zs.ZAdd(0, Z{1, "a"}, Z{2, "b"}, Z{3, "c"}, Z{4, "d"})
+
+	a.Equal(zs.ZPopMin(1), []Z{{1, "a"}})
+	a.Equal(zs.ZPopMax(2), []Z{{4, "d"}, {3, "c"}})
+	a.Equal(zs.ZPopMin(0), nil)
+	a.Equal(zs.ZPopMin(10), []Z{{2, "b"}})
+	a.Equal(zs.ZPopMax(1), nil)
+	a.Equal(zs.ZCard(), 0)
+
+	_, ok := zs.ZScore("a")
+	a.Assert(!ok)
+}